@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"testing"
 	"time"
+
+	"github.com/openacid/slim/marshal"
 )
 
 func newByteArray32(eSize int, index []uint32, elts [][]byte) (*Array32, error) {
@@ -110,3 +112,70 @@ func BenchmarkMemOverhead(b *testing.B) {
 		b.Run("", benchMemOverHead(c.eltSize, c.maxIdx))
 	}
 }
+
+func BenchmarkArray32GetAfterSet(b *testing.B) {
+
+	n := 1 << 20
+	index := make([]uint32, n)
+	elts := make([]uint32, n)
+	for i := range index {
+		index[i] = uint32(i * 16)
+		elts[i] = uint32(i)
+	}
+
+	a, err := NewU32(index, elts)
+	if err != nil {
+		b.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	// Offsets of all words after word 0 are pending.
+	a.Set(1, uint32(1))
+
+	rnd := rand.New(rand.NewSource(0))
+	keys := make([]uint32, 1024)
+	for i := range keys {
+		keys[i] = index[rnd.Intn(n)]
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		a.Get2(keys[i&1023])
+	}
+}
+
+func BenchmarkArray32Get2(b *testing.B) {
+
+	n := 1 << 16
+	index := make([]uint32, n)
+	u32s := make([]uint32, n)
+	u64s := make([]uint64, n)
+	strs := make([]string, n)
+	for i := range index {
+		index[i] = uint32(i * 4)
+		u32s[i] = uint32(i)
+		u64s[i] = uint64(i % 32)
+		strs[i] = fmt.Sprintf("%d", i)
+	}
+
+	for _, c := range []struct {
+		name string
+		conv Converter
+		elts interface{}
+	}{
+		{"fixed", U32Conv{}, u32s},
+		{"varlen", marshal.String16{}, strs},
+		{"packed", PackedConv{Width: 5}, u64s},
+	} {
+		a, err := New(c.conv, index, c.elts)
+		if err != nil {
+			b.Fatalf("failed new compacted array, err: %s", err)
+		}
+
+		b.Run(c.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				a.Get2(index[i&(1<<16-1)])
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package array
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+var OutputEliasFano uint64
+
+// benchEliasFanoValues are 1<<20 values with random gaps in [0, 1<<12].
+var benchEliasFanoValues = func() []uint64 {
+	rnd := rand.New(rand.NewSource(1))
+	values := make([]uint64, 1<<20)
+	v := uint64(0)
+	for i := range values {
+		v += uint64(rnd.Int63n(1<<12 + 1))
+		values[i] = v
+	}
+	return values
+}()
+
+func BenchmarkEliasFanoGet(b *testing.B) {
+
+	values := benchEliasFanoValues
+	ef, _ := NewEliasFano(values)
+	mask := uint32(len(values) - 1)
+
+	b.ResetTimer()
+
+	var s uint64
+	for i := 0; i < b.N; i++ {
+		s += ef.Get(uint32(i) & mask)
+	}
+	OutputEliasFano = s
+}
+
+func BenchmarkEliasFanoNextGEQ(b *testing.B) {
+
+	values := benchEliasFanoValues
+	ef, _ := NewEliasFano(values)
+	mask := len(values) - 1
+
+	b.ResetTimer()
+
+	var s uint64
+	for i := 0; i < b.N; i++ {
+		_, v, _ := ef.NextGEQ(values[i&mask] - 1)
+		s += v
+	}
+	OutputEliasFano = s
+}
+
+func BenchmarkEliasFanoRange(b *testing.B) {
+
+	values := benchEliasFanoValues
+	ef, _ := NewEliasFano(values)
+
+	b.ResetTimer()
+
+	var s uint64
+	for i := 0; i < b.N; {
+		ef.Range(func(j uint32, v uint64) bool {
+			s += v
+			i++
+			return i < b.N
+		})
+	}
+	OutputEliasFano = s
+}
+
+// BenchmarkSliceSearch is the baseline of NextGEQ with a plain slice.
+func BenchmarkSliceSearch(b *testing.B) {
+
+	values := benchEliasFanoValues
+	mask := len(values) - 1
+
+	b.ResetTimer()
+
+	var s uint64
+	for i := 0; i < b.N; i++ {
+		x := values[i&mask] - 1
+		j := sort.Search(len(values), func(j int) bool { return values[j] >= x })
+		s += values[j]
+	}
+	OutputEliasFano = s
+}
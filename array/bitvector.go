@@ -0,0 +1,75 @@
+package array
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/bits"
+	"github.com/openacid/slim/prototype"
+)
+
+// BitVector is a bits.Vector that is serialized with the `serialize` package
+// as a protobuf message.
+//
+// bits.Vector has no dependency, thus it is converted to and from
+// prototype.BitVectorStorage here.
+type BitVector struct {
+	bits.Vector
+}
+
+// NewBitVector creates a BitVector of bits in `bs`.
+func NewBitVector(bs []bool) *BitVector {
+	return &BitVector{Vector: *bits.NewVector(bs)}
+}
+
+// storage returns the protobuf storage of the vector.
+func (v *BitVector) storage() *prototype.BitVectorStorage {
+	return &prototype.BitVectorStorage{
+		N:            v.N,
+		Ones:         v.Ones,
+		Words:        v.Words,
+		Ranks:        v.Ranks,
+		Select1Hints: v.Select1Hints,
+		Select0Hints: v.Select0Hints,
+	}
+}
+
+// Reset clears all bits.
+func (v *BitVector) Reset() {
+	v.Vector = bits.Vector{}
+}
+
+// String returns a text representation of the protobuf storage.
+func (v *BitVector) String() string {
+	return proto.CompactTextString(v.storage())
+}
+
+// ProtoMessage implements proto.Message.
+func (v *BitVector) ProtoMessage() {}
+
+// XXX_Size returns the size of the protobuf storage.
+func (v *BitVector) XXX_Size() int {
+	return v.storage().XXX_Size()
+}
+
+// XXX_Marshal marshals the vector as a prototype.BitVectorStorage.
+func (v *BitVector) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return v.storage().XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the vector from a prototype.BitVectorStorage.
+func (v *BitVector) XXX_Unmarshal(b []byte) error {
+
+	s := &prototype.BitVectorStorage{}
+	if err := proto.Unmarshal(b, s); err != nil {
+		return err
+	}
+
+	v.Vector = bits.Vector{
+		N:            s.N,
+		Ones:         s.Ones,
+		Words:        s.Words,
+		Ranks:        s.Ranks,
+		Select1Hints: s.Select1Hints,
+		Select0Hints: s.Select0Hints,
+	}
+	return nil
+}
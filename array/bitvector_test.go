@@ -0,0 +1,64 @@
+package array
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/openacid/slim/serialize"
+)
+
+func TestBitVectorSerialize(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+	bs := make([]bool, 3000)
+	for i := range bs {
+		bs[i] = rnd.Float64() < 0.4
+	}
+
+	v := NewBitVector(bs)
+
+	buf := new(bytes.Buffer)
+	n, err := serialize.Marshal(buf, v)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if n != serialize.GetMarshalSize(v) {
+		t.Fatalf("expect size: %d but: %d", serialize.GetMarshalSize(v), n)
+	}
+
+	loaded := &BitVector{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(v.Vector, loaded.Vector) {
+		t.Fatalf("expect loaded vector to be the same")
+	}
+
+	// appending after loading keeps ranks and hints consistent
+	for i := 0; i < 2000; i++ {
+		b := rnd.Float64() < 0.6
+		bs = append(bs, b)
+		v.Append(b)
+		loaded.Append(b)
+	}
+	if !reflect.DeepEqual(v.Vector, loaded.Vector) {
+		t.Fatalf("expect vector appended after loading to be the same")
+	}
+
+	ones := uint64(0)
+	for i, b := range bs {
+		if r := loaded.Rank1(uint64(i)); r != ones {
+			t.Fatalf("Rank1(%d) expect: %d but: %d", i, ones, r)
+		}
+		if b {
+			ones++
+		}
+	}
+
+	loaded.Reset()
+	if loaded.Len() != 0 {
+		t.Fatalf("expect empty vector after Reset but: %d", loaded.Len())
+	}
+}
@@ -0,0 +1,230 @@
+package array
+
+import (
+	"errors"
+	"reflect"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/prototype"
+)
+
+// ErrColumnsLen is returned if the number of elts slices does not equal the
+// number of columns, when initializing a Columns.
+var ErrColumnsLen = errors.New("the number of columns and elts must be equal")
+
+// ErrColumnMismatch is returned if columns in serialized data are not the
+// columns declared by NewColumns.
+var ErrColumnMismatch = errors.New("columns do not match declared columns")
+
+// Column declares a named column of Columns and the Converter of its elts.
+type Column struct {
+	Name string
+	Converter
+}
+
+// Columns is a space efficient table of several columns, such as parallel
+// attributes of the same sparse positions.
+//
+// Unlike several Array32, all columns share one Array32Index.
+// Every column converts elts with its own Converter, and supports var-length
+// elts and EltsConverter as Array32 does.
+//
+// It is serialized as a single protobuf message.
+// To load it, create it with the same columns by NewColumns first.
+type Columns struct {
+	Array32Index
+
+	names []string
+
+	// cols stores elts of every column.
+	// Their Array32Index are empty except Cnt.
+	cols []*Array32
+}
+
+// NewColumns creates an empty Columns with columns `cols`.
+func NewColumns(cols ...Column) *Columns {
+
+	c := &Columns{}
+	for _, col := range cols {
+		c.names = append(c.names, col.Name)
+		c.cols = append(c.cols, &Array32{Converter: col.Converter})
+	}
+
+	return c
+}
+
+// Init initializes it with a slice of index and a slice of elts for every
+// column, in the order columns are declared.
+//
+// The indexes parameter must be a ascending array of type unit32,
+// otherwise, return the ErrIndexNotAscending error
+func (c *Columns) Init(indexes []uint32, elts ...interface{}) error {
+
+	if len(elts) != len(c.cols) {
+		return ErrColumnsLen
+	}
+
+	rElts := make([]reflect.Value, len(elts))
+	for i, e := range elts {
+		rElts[i] = reflect.ValueOf(e)
+		if rElts[i].Kind() != reflect.Slice {
+			panic("input is not a slice")
+		}
+		if rElts[i].Len() != len(indexes) {
+			return ErrIndexLen
+		}
+	}
+
+	err := c.InitIndexBitmap(indexes)
+	if err != nil {
+		return err
+	}
+
+	for i, col := range c.cols {
+		col.Cnt = c.Cnt
+		col.initElts(rElts[i])
+	}
+
+	return nil
+}
+
+// Names returns the names of columns.
+func (c *Columns) Names() []string {
+	return c.names
+}
+
+// Get returns the value of column `col` indexed by idx if it is in array, else
+// return nil.
+func (c *Columns) Get(idx uint32, col string) interface{} {
+	v, _ := c.Get2(idx, col)
+	return v
+}
+
+// Get2 returns the value of column `col` indexed by `idx` and a bool
+// indicating existence.
+// If `idx` or `col` does not present it returns `nil, false`.
+func (c *Columns) Get2(idx uint32, col string) (interface{}, bool) {
+
+	i := c.column(col)
+	if i < 0 {
+		return nil, false
+	}
+
+	pos, ok := c.GetEltIndex(idx)
+	if !ok {
+		return nil, false
+	}
+
+	return c.cols[i].eltAt(pos), true
+}
+
+// GetRow returns values of all columns indexed by `idx` and a bool indicating
+// existence.
+// If `idx` does not present it returns `nil, false`.
+func (c *Columns) GetRow(idx uint32) ([]interface{}, bool) {
+
+	pos, ok := c.GetEltIndex(idx)
+	if !ok {
+		return nil, false
+	}
+
+	row := make([]interface{}, len(c.cols))
+	for i, col := range c.cols {
+		row[i] = col.eltAt(pos)
+	}
+
+	return row, true
+}
+
+// column returns the position of column `name` or -1.
+func (c *Columns) column(name string) int {
+	for i, n := range c.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// storage returns the protobuf storage of index and all columns.
+func (c *Columns) storage() *prototype.ColumnsStorage {
+
+	idx := c.freshStorage()
+	s := &prototype.ColumnsStorage{
+		Cnt:     idx.Cnt,
+		Bitmaps: idx.Bitmaps,
+		Offsets: idx.Offsets,
+	}
+
+	for i, col := range c.cols {
+		s.Columns = append(s.Columns, &prototype.ColumnStorage{
+			Name:       c.names[i],
+			Elts:       col.Elts,
+			EltOffsets: col.EltOffsets,
+		})
+	}
+
+	return s
+}
+
+// Reset clears the index and elts, but keeps declared columns.
+func (c *Columns) Reset() {
+	c.Array32Index.Reset()
+	for _, col := range c.cols {
+		col.Array32Index.Reset()
+	}
+}
+
+// String returns a text representation of the protobuf storage.
+func (c *Columns) String() string {
+	return proto.CompactTextString(c.storage())
+}
+
+// XXX_Size returns the size of the protobuf storage.
+func (c *Columns) XXX_Size() int {
+	return c.storage().XXX_Size()
+}
+
+// XXX_Marshal marshals index and all columns as a single protobuf message.
+func (c *Columns) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	s := c.storage()
+
+	// protobuf marshals embedded messages with sizes cached by XXX_Size.
+	s.XXX_Size()
+	return s.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads index and all columns.
+// It returns ErrColumnMismatch if columns in `b` are not the declared ones.
+func (c *Columns) XXX_Unmarshal(b []byte) error {
+
+	s := &prototype.ColumnsStorage{}
+	if err := proto.Unmarshal(b, s); err != nil {
+		return err
+	}
+
+	if len(s.Columns) != len(c.cols) {
+		return ErrColumnMismatch
+	}
+	for i, cs := range s.Columns {
+		if cs.Name != c.names[i] {
+			return ErrColumnMismatch
+		}
+	}
+
+	c.Cnt = s.Cnt
+	c.Bitmaps = s.Bitmaps
+	c.Offsets = s.Offsets
+	c.pending = nil
+	c.wordOffsets = nil
+
+	for i, cs := range s.Columns {
+		col := c.cols[i]
+		col.Cnt = s.Cnt
+		col.Elts = cs.Elts
+		col.EltOffsets = cs.EltOffsets
+		col.resolveElts()
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package array
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/openacid/slim/marshal"
+	"github.com/openacid/slim/serialize"
+)
+
+func newTestColumns() *Columns {
+	return NewColumns(
+		Column{Name: "size", Converter: U32Conv{}},
+		Column{Name: "shard", Converter: PackedConv{Width: 5}},
+		Column{Name: "owner", Converter: marshal.String16{}},
+	)
+}
+
+func TestColumns(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203, 1000}
+	sizes := []uint32{10, 20, 30, 40, 50}
+	shards := []uint64{3, 31, 0, 7, 3}
+	owners := []string{"alice", "", "bob", "carol", "alice"}
+
+	c := newTestColumns()
+	if err := c.Init(index, sizes, shards, owners); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	check := func(c *Columns) {
+		if !reflect.DeepEqual(c.Names(), []string{"size", "shard", "owner"}) {
+			t.Fatalf("wrong names: %v", c.Names())
+		}
+
+		for i, idx := range index {
+			want := []interface{}{sizes[i], shards[i], owners[i]}
+
+			row, found := c.GetRow(idx)
+			if !found || !reflect.DeepEqual(want, row) {
+				t.Fatalf("GetRow i:%d expect: %v, act: %v %v", idx, want, row, found)
+			}
+
+			for j, name := range c.Names() {
+				v, found := c.Get2(idx, name)
+				if !found || v != want[j] {
+					t.Fatalf("Get2 i:%d %s expect: %v, act: %v %v", idx, name, want[j], v, found)
+				}
+			}
+		}
+
+		if v := c.Get(2, "size"); v != nil {
+			t.Fatalf("expect nil for absent index but: %v", v)
+		}
+		if v := c.Get(1, "foo"); v != nil {
+			t.Fatalf("expect nil for absent column but: %v", v)
+		}
+		if _, found := c.GetRow(2); found {
+			t.Fatalf("expect absent row 2")
+		}
+	}
+
+	check(c)
+
+	buf := new(bytes.Buffer)
+	n, err := serialize.Marshal(buf, c)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if n != serialize.GetMarshalSize(c) {
+		t.Fatalf("expect size: %d but: %d", serialize.GetMarshalSize(c), n)
+	}
+
+	data := buf.Bytes()
+
+	loaded := newTestColumns()
+	if err := serialize.Unmarshal(bytes.NewReader(data), loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	check(loaded)
+
+	other := NewColumns(Column{Name: "size", Converter: U32Conv{}})
+	err = serialize.Unmarshal(bytes.NewReader(data), other)
+	if err != ErrColumnMismatch {
+		t.Fatalf("expect ErrColumnMismatch but: %v", err)
+	}
+}
+
+func TestColumnsSharedIndex(t *testing.T) {
+
+	index := []uint32{}
+	a, b := []uint32{}, []uint32{}
+	for i := uint32(0); i < 10000; i += 3 {
+		index = append(index, i)
+		a = append(a, i)
+		b = append(b, i*2)
+	}
+
+	c := NewColumns(Column{Name: "a", Converter: U32Conv{}}, Column{Name: "b", Converter: U32Conv{}})
+	if err := c.Init(index, a, b); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	arrA, _ := NewU32(index, a)
+	arrB, _ := NewU32(index, b)
+
+	size := serialize.GetMarshalSize(c)
+	sep := serialize.GetMarshalSize(arrA) + serialize.GetMarshalSize(arrB)
+	indexSize := serialize.GetMarshalSize(&arrA.Array32Index) - int64(len(arrA.Elts))
+	if size > sep-indexSize/2 {
+		t.Fatalf("expect index stored once: columns: %d, arrays: %d", size, sep)
+	}
+}
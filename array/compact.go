@@ -3,6 +3,7 @@ package array
 
 import (
 	"errors"
+	mbits "math/bits"
 	"reflect"
 
 	"github.com/openacid/slim/version"
@@ -12,13 +13,27 @@ import (
 // datas, when initializing a Array.
 var ErrIndexLen = errors.New("the length of indexes and elts must be equal")
 
+// eltOffsetSample is the interval of elts between two EltOffsets, for
+// var-length elts.
+const eltOffsetSample = 16
+
 // Array32 is a space efficient array implementation.
 //
 // Unlike a normal array, it does not allocate space for a element that there is
 // not data in it.
+//
+// Elts may be var-length, e.g. strings with marshal.String16.
+// In this case it stores in EltOffsets the offset in Elts of every 16-th elt,
+// and locates an elt by skipping at most 15 elts after the sampled one with
+// Converter.GetMarshaledSize.
 type Array32 struct {
 	Array32Index
 	Converter
+
+	// eltSize is the size of every elt, resolved by resolveElts when elts are
+	// fixed-size and the Converter is not an EltsConverter.
+	// It is 0 if an elt must be located with eltStore.
+	eltSize uint32
 }
 
 // NewU32 creates a Array32 instance with uint32 element.
@@ -67,16 +82,42 @@ func (a *Array32) Init(indexes []uint32, elts interface{}) error {
 		return err
 	}
 
-	a.Elts = make([]byte, 0, nElts)
-	for i := 0; i < nElts; i++ {
-		ee := rElts.Index(i).Interface()
-		raw := a.Marshal(ee)
-		a.Elts = append(a.Elts, raw...)
-	}
+	// Resolve the name once here, marshaling only reads it.
+	a.ConverterName, _ = ConverterName(a.Converter)
 
+	a.initElts(rElts)
 	return nil
 }
 
+// initElts converts elts in slice `rElts` into Elts.
+// a.Cnt must be the number of elts.
+func (a *Array32) initElts(rElts reflect.Value) {
+	st := newEltStore(a.Converter, rElts)
+	a.Elts, a.EltOffsets = st.elts, st.eltOffsets
+	a.resolveElts()
+}
+
+// resolveElts resolves how to locate an elt once, so that Get does not.
+// It must be called after Elts, EltOffsets, Cnt or Converter changes.
+func (a *Array32) resolveElts() {
+	a.eltSize = 0
+
+	if _, ok := a.Converter.(EltsConverter); ok || a.isVarLen() || a.Cnt == 0 {
+		return
+	}
+	a.eltSize = uint32(len(a.Elts)) / a.Cnt
+}
+
+// store returns the elts part of the array.
+func (a *Array32) store() eltStore {
+	return eltStore{
+		conv:       a.Converter,
+		cnt:        a.Cnt,
+		elts:       a.Elts,
+		eltOffsets: a.EltOffsets,
+	}
+}
+
 // Get returns the value indexed by idx if it is in array, else return nil
 func (a *Array32) Get(idx uint32) interface{} {
 	v, _ := a.Get2(idx)
@@ -86,25 +127,281 @@ func (a *Array32) Get(idx uint32) interface{} {
 // Get2 returns the value indexed by `idx` and a bool indicating existence.
 // If `idx` does not present it returns `nil, false`.
 func (a *Array32) Get2(idx uint32) (interface{}, bool) {
-	raw, ok := a.GetBytes(idx, a.GetMarshaledSize(nil))
-	if ok {
-		_, val := a.Unmarshal(raw)
-		return val, true
+	pos, ok := a.GetEltIndex(idx)
+	if !ok {
+		return nil, false
 	}
 
-	return nil, false
+	return a.eltAt(pos), true
+}
+
+// eltAt returns the elt at position `pos` in Elts.
+func (a *Array32) eltAt(pos uint32) interface{} {
+	if a.eltSize != 0 {
+		st := pos * a.eltSize
+		_, v := a.Unmarshal(a.Elts[st : st+a.eltSize])
+		return v
+	}
+	return a.store().eltAt(pos)
 }
 
 // GetBytes is similar to Get2 but does not return the byte slice instead of
 // unmarshaled data.
+// `eltsize` is ignored if elts are var-length.
+//
+// It does not apply to an EltsConverter, with which an elt may not take whole
+// bytes.
 func (a *Array32) GetBytes(idx uint32, eltsize int) ([]byte, bool) {
 	dataIndex, ok := a.GetEltIndex(idx)
 	if !ok {
 		return nil, false
 	}
 
-	stIdx := uint32(eltsize) * dataIndex
-	return a.Elts[stIdx : stIdx+uint32(eltsize)], true
+	return a.store().eltBytes(dataIndex, eltsize), true
+}
+
+// Range calls `fn` for every present index and its value, in ascending index
+// order, until `fn` returns false.
+//
+// It scans set bits in Bitmaps, thus the cost is proportional to the number of
+// elts and bitmap words, rather than to the max index.
+func (a *Array32) Range(fn func(idx uint32, v interface{}) bool) {
+
+	ec, isElts := a.Converter.(EltsConverter)
+
+	pos, ord := 0, uint32(0)
+	for iBm, bmWord := range a.Bitmaps {
+		for bmWord != 0 {
+			iBit := uint32(mbits.TrailingZeros64(bmWord))
+			bmWord &= bmWord - 1
+
+			var v interface{}
+			if isElts {
+				v = ec.UnmarshalElt(a.Elts, ord)
+				ord++
+			} else {
+				var n int
+				n, v = a.Unmarshal(a.Elts[pos:])
+				pos += n
+			}
+
+			if !fn(uint32(iBm)*bmWidth+iBit, v) {
+				return
+			}
+		}
+	}
+}
+
+// Project returns a new array with the elts of `a` whose index is present in
+// `sub`.
+// The returned array shares the Converter with `a`.
+func (a *Array32) Project(sub *Array32Index) *Array32 {
+
+	idx := a.Intersect(sub)
+	rst := &Array32{Array32Index: *idx, Converter: a.Converter}
+	rst.Elts = make([]byte, 0)
+
+	if ec, ok := a.Converter.(EltsConverter); ok {
+		kept := []interface{}{}
+		a.Range(func(idx uint32, v interface{}) bool {
+			if rst.Has(idx) {
+				kept = append(kept, v)
+			}
+			return true
+		})
+		rst.Elts = ec.MarshalElts(kept)
+		return rst
+	}
+
+	pos := 0
+	for iBm, bmWord := range a.Bitmaps {
+		var keep uint64
+		if iBm < len(rst.Bitmaps) {
+			keep = rst.Bitmaps[iBm]
+		}
+
+		for bmWord != 0 {
+			bit := bmWord & -bmWord
+			bmWord &^= bit
+
+			n := a.GetMarshaledSize(a.Elts[pos:])
+			if keep&bit != 0 {
+				rst.Elts = append(rst.Elts, a.Elts[pos:pos+n]...)
+			}
+			pos += n
+		}
+	}
+
+	if a.isVarLen() {
+		rst.initEltOffsets()
+	}
+	rst.resolveElts()
+
+	return rst
+}
+
+// Set sets the value of `idx` to `v`.
+// If `idx` is not present, it inserts `v` into Elts.
+//
+// It moves all elts after `idx` in Elts, but does not update Offsets of
+// bitmap words after `idx` until the next Set, Delete or RebuildOffsets.
+//
+// With an EltsConverter it re-encodes all elts.
+func (a *Array32) Set(idx uint32, v interface{}) {
+	defer a.resolveElts()
+
+	if ec, ok := a.Converter.(EltsConverter); ok {
+		elts := a.eltsOf(ec)
+		pos, added := a.setIndex(idx)
+		if added {
+			elts = append(elts, nil)
+			copy(elts[pos+1:], elts[pos:])
+		}
+		elts[pos] = v
+		a.Elts = ec.MarshalElts(elts)
+		return
+	}
+
+	raw := a.Marshal(v)
+	eltsize := uint32(len(raw))
+
+	if !a.isVarLen() && a.Cnt > 0 && int(eltsize) != a.fixedEltSize() {
+		a.initEltOffsets()
+	}
+
+	pos, added := a.setIndex(idx)
+
+	if a.isVarLen() {
+		st := a.eltOffset(pos)
+		end := st
+		if !added {
+			end += uint32(a.GetMarshaledSize(a.Elts[st:]))
+		}
+		a.Elts = append(a.Elts[:st], append(raw, a.Elts[end:]...)...)
+		a.rebuildEltOffsets(pos)
+		return
+	}
+
+	st := pos * eltsize
+
+	if !added {
+		copy(a.Elts[st:st+eltsize], raw)
+		return
+	}
+
+	a.Elts = append(a.Elts, raw...)
+	copy(a.Elts[st+eltsize:], a.Elts[st:])
+	copy(a.Elts[st:], raw)
+}
+
+// Delete removes the value of `idx`.
+// It returns true if `idx` is present.
+func (a *Array32) Delete(idx uint32) bool {
+	defer a.resolveElts()
+
+	ec, isElts := a.Converter.(EltsConverter)
+
+	var elts []interface{}
+	if isElts {
+		elts = a.eltsOf(ec)
+	}
+
+	eltsize := uint32(a.fixedEltSize())
+
+	pos, removed := a.deleteIndex(idx)
+	if !removed {
+		return false
+	}
+
+	if isElts {
+		elts = append(elts[:pos], elts[pos+1:]...)
+		a.Elts = ec.MarshalElts(elts)
+		return true
+	}
+
+	if a.isVarLen() {
+		st := a.eltOffset(pos)
+		end := st + uint32(a.GetMarshaledSize(a.Elts[st:]))
+		a.Elts = append(a.Elts[:st], a.Elts[end:]...)
+		a.rebuildEltOffsets(pos)
+		return true
+	}
+
+	st := pos * eltsize
+	a.Elts = append(a.Elts[:st], a.Elts[st+eltsize:]...)
+	return true
+}
+
+// isVarLen returns true if elts are var-length and EltOffsets is used.
+func (a *Array32) isVarLen() bool {
+	return len(a.EltOffsets) > 0
+}
+
+// fixedEltSize returns the size of every elt if they are fixed-length.
+func (a *Array32) fixedEltSize() int {
+	return a.store().fixedEltSize()
+}
+
+// eltOffset returns the offset in Elts of the `i`-th elt.
+func (a *Array32) eltOffset(i uint32) uint32 {
+	return a.store().eltOffset(i)
+}
+
+// initEltOffsets builds EltOffsets for var-length elts.
+func (a *Array32) initEltOffsets() {
+	st := a.store()
+	st.initEltOffsets()
+	a.EltOffsets = st.eltOffsets
+}
+
+// rebuildEltOffsets rebuilds EltOffsets after the `i`-th elt.
+func (a *Array32) rebuildEltOffsets(i uint32) {
+	st := a.store()
+	st.rebuildEltOffsets(i)
+	a.EltOffsets = st.eltOffsets
+}
+
+// eltsOf returns all elts encoded with an EltsConverter.
+func (a *Array32) eltsOf(ec EltsConverter) []interface{} {
+	elts := make([]interface{}, a.Cnt)
+	for i := range elts {
+		elts[i] = ec.UnmarshalElt(a.Elts, uint32(i))
+	}
+	return elts
+}
+
+// XXX_Unmarshal loads the array and checks the Converter against the
+// ConverterName in data.
+//
+// If no Converter is specified, it uses the one registered with ConverterName,
+// or returns ErrUnknownConverter.
+// If the specified Converter is not registered with ConverterName, it returns
+// ErrConverterMismatch.
+// Data without ConverterName is loaded with the specified Converter.
+func (a *Array32) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+	defer a.resolveElts()
+
+	if a.ConverterName == "" {
+		return nil
+	}
+
+	if a.Converter == nil {
+		conv, ok := ConverterByName(a.ConverterName)
+		if !ok {
+			return ErrUnknownConverter
+		}
+		a.Converter = conv
+		return nil
+	}
+
+	if name, _ := ConverterName(a.Converter); name != a.ConverterName {
+		return ErrConverterMismatch
+	}
+	return nil
 }
 
 // GetVersion returns a Version to identify this data type: "a32"
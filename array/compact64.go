@@ -0,0 +1,114 @@
+package array
+
+import (
+	"reflect"
+
+	"github.com/openacid/slim/version"
+)
+
+// Array64 is a space efficient array with 64-bit indexes, such as hashes or
+// global sequence numbers.
+//
+// Like Array32, it does not allocate space for a element that there is not data
+// in it.
+// And the space it takes does not depend on how great the indexes are.
+//
+// Elts are stored the same way as Array32 does, thus var-length elts and an
+// EltsConverter work too.
+type Array64 struct {
+	Array64Index
+	Converter
+}
+
+// New64 creates a Array64 and initializes it with a slice of index and a
+// slice of data.
+//
+// The indexes parameter must be a ascending array of type unit64,
+// otherwise, return the ErrIndexNotAscending error
+func New64(conv Converter, indexes []uint64, elts interface{}) (ca *Array64, err error) {
+
+	ca = &Array64{
+		Converter: conv,
+	}
+
+	err = ca.Init(indexes, elts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ca, nil
+}
+
+// Init initializes a compacted array from the slice type elts
+// the indexes parameter must be a ascending array of type unit64,
+// otherwise, return the ErrIndexNotAscending error
+func (a *Array64) Init(indexes []uint64, elts interface{}) error {
+
+	rElts := reflect.ValueOf(elts)
+	if rElts.Kind() != reflect.Slice {
+		panic("input is not a slice")
+	}
+
+	nElts := rElts.Len()
+
+	if len(indexes) != nElts {
+		return ErrIndexLen
+	}
+
+	err := a.InitIndexBitmap(indexes)
+	if err != nil {
+		return err
+	}
+
+	st := newEltStore(a.Converter, rElts)
+	a.Elts, a.EltOffsets = st.elts, st.eltOffsets
+
+	return nil
+}
+
+// Get returns the value indexed by idx if it is in array, else return nil
+func (a *Array64) Get(idx uint64) interface{} {
+	v, _ := a.Get2(idx)
+	return v
+}
+
+// Get2 returns the value indexed by `idx` and a bool indicating existence.
+// If `idx` does not present it returns `nil, false`.
+func (a *Array64) Get2(idx uint64) (interface{}, bool) {
+	pos, ok := a.GetEltIndex(idx)
+	if !ok {
+		return nil, false
+	}
+
+	return a.store().eltAt(pos), true
+}
+
+// GetBytes is similar to Get2 but does not return the byte slice instead of
+// unmarshaled data.
+// `eltsize` is ignored if elts are var-length.
+//
+// It does not apply to an EltsConverter, with which an elt may not take whole
+// bytes.
+func (a *Array64) GetBytes(idx uint64, eltsize int) ([]byte, bool) {
+	dataIndex, ok := a.GetEltIndex(idx)
+	if !ok {
+		return nil, false
+	}
+
+	return a.store().eltBytes(dataIndex, eltsize), true
+}
+
+// store returns the elts part of the array.
+func (a *Array64) store() eltStore {
+	return eltStore{
+		conv:       a.Converter,
+		cnt:        a.Cnt,
+		elts:       a.Elts,
+		eltOffsets: a.EltOffsets,
+	}
+}
+
+// GetVersion returns a Version to identify this data type: "a64"
+func (a *Array64) GetVersion() version.Version {
+	return "a64"
+}
@@ -0,0 +1,189 @@
+package array
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/marshal"
+	"github.com/openacid/slim/prototype"
+	"github.com/openacid/slim/serialize"
+)
+
+func TestArray64Get(t *testing.T) {
+
+	index := []uint64{0, 1, 63, 64, 1 << 32, 1<<32 + 1, 1 << 40, 1<<63 - 1, math.MaxUint64}
+	eltsData := []uint32{}
+	for i := range index {
+		eltsData = append(eltsData, uint32(i*10))
+	}
+
+	ca, err := New64(U32Conv{}, index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	if ca.Cnt != uint32(len(index)) {
+		t.Fatalf("cnt is not equal expect: %d, act: %d", len(index), ca.Cnt)
+	}
+
+	for i, idx := range index {
+		v, found := ca.Get2(idx)
+		if !found || v.(uint32) != eltsData[i] {
+			t.Fatalf("Get2 i:%d expect: %d, act: %v %v", idx, eltsData[i], v, found)
+		}
+		if !ca.Has(idx) {
+			t.Fatalf("Has i:%d expect true", idx)
+		}
+	}
+
+	for _, idx := range []uint64{2, 62, 65, 128, 1<<32 - 1, 1<<32 + 2, 1<<40 + 64, math.MaxUint64 - 1} {
+		if v := ca.Get(idx); v != nil {
+			t.Fatalf("Get i:%d expect nil, act: %v", idx, v)
+		}
+		if ca.Has(idx) {
+			t.Fatalf("Has i:%d expect false", idx)
+		}
+	}
+}
+
+func TestArray64Random(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	keysMap := map[uint64]uint32{}
+	for len(keysMap) < 4096 {
+		// dense and sparse indexes
+		idx := rnd.Uint64()
+		if rnd.Intn(2) == 0 {
+			idx = uint64(rnd.Intn(8192))
+		}
+		keysMap[idx] = rnd.Uint32()
+	}
+
+	index := []uint64{}
+	for idx := range keysMap {
+		index = append(index, idx)
+	}
+	sort.Slice(index, func(i, j int) bool { return index[i] < index[j] })
+
+	eltsData := []uint32{}
+	for _, idx := range index {
+		eltsData = append(eltsData, keysMap[idx])
+	}
+
+	ca, err := New64(U32Conv{}, index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	for idx, want := range keysMap {
+		v, found := ca.Get2(idx)
+		if !found || v.(uint32) != want {
+			t.Fatalf("Get2 i:%d expect: %d, act: %v %v", idx, want, v, found)
+		}
+	}
+
+	for i := 0; i < 8192; i++ {
+		idx := uint64(i)
+		_, present := keysMap[idx]
+		if ca.Has(idx) != present {
+			t.Fatalf("Has i:%d expect: %v", idx, present)
+		}
+	}
+}
+
+func TestArray64Serialize(t *testing.T) {
+
+	index := []uint64{3, 1 << 33, 1 << 60}
+	eltsData := []uint16{7, 8, 9}
+
+	ca, err := New64(U16Conv{}, index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	buf := new(bytes.Buffer)
+	n, err := serialize.Marshal(buf, ca)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if n != serialize.GetMarshalSize(ca) {
+		t.Fatalf("expect size: %d but: %d", serialize.GetMarshalSize(ca), n)
+	}
+
+	loaded := &Array64{Converter: U16Conv{}}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v.(uint16) != eltsData[i] {
+			t.Fatalf("Get2 i:%d expect: %d, act: %v %v", idx, eltsData[i], v, found)
+		}
+	}
+	if loaded.Has(4) {
+		t.Fatalf("expect 4 not in array")
+	}
+
+	// field 1 to 4 are the same as Array32Storage
+	b, err := proto.Marshal(ca)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	st := &prototype.Array32Storage{}
+	if err := proto.Unmarshal(b, st); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if st.Cnt != ca.Cnt ||
+		!reflect.DeepEqual(st.Bitmaps, ca.Bitmaps) ||
+		!reflect.DeepEqual(st.Offsets, ca.Offsets) ||
+		!bytes.Equal(st.Elts, ca.Elts) {
+		t.Fatalf("expect the same Cnt, Bitmaps, Offsets, Elts as %v but: %v", ca, st)
+	}
+}
+
+func TestArray64VarLength(t *testing.T) {
+
+	index := []uint64{}
+	elts := []string{}
+	for i := uint64(0); i < 100; i++ {
+		index = append(index, i*i<<30)
+		elts = append(elts, strings.Repeat("x", int(i%7)))
+	}
+
+	for _, conv := range []Converter{marshal.String16{}, DictConv{Converter: marshal.String16{}}} {
+
+		ca, err := New64(conv, index, elts)
+		if err != nil {
+			t.Fatalf("%T failed new compacted array, err: %s", conv, err)
+		}
+
+		buf := new(bytes.Buffer)
+		if _, err := serialize.Marshal(buf, ca); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		loaded := &Array64{Converter: conv}
+		if err := serialize.Unmarshal(buf, loaded); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for _, a := range []*Array64{ca, loaded} {
+			for i, idx := range index {
+				v, found := a.Get2(idx)
+				if !found || v.(string) != elts[i] {
+					t.Fatalf("%T Get2 i:%d expect: %q, act: %v %v", conv, idx, elts[i], v, found)
+				}
+			}
+			if v, found := a.Get2(3); found {
+				t.Fatalf("%T Get2 i:3 expect not found but: %v", conv, v)
+			}
+		}
+	}
+}
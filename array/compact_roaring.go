@@ -0,0 +1,113 @@
+package array
+
+import (
+	"reflect"
+
+	"github.com/openacid/slim/version"
+)
+
+// RoaringArray is a space efficient array like Array32, with a RoaringIndex
+// instead of an Array32Index.
+//
+// It fits indexes that are very sparse, such as a few indexes scattered in the
+// whole uint32 space, or very dense, such as long runs of consecutive indexes.
+//
+// Elts are stored the same way as Array32 does, thus var-length elts and an
+// EltsConverter work too.
+type RoaringArray struct {
+	RoaringIndex
+	Converter
+}
+
+// NewRoaring creates a RoaringArray and initializes it with a slice of index
+// and a slice of data.
+//
+// The indexes parameter must be a ascending array of type unit32,
+// otherwise, return the ErrIndexNotAscending error
+func NewRoaring(conv Converter, indexes []uint32, elts interface{}) (ca *RoaringArray, err error) {
+
+	ca = &RoaringArray{
+		Converter: conv,
+	}
+
+	err = ca.Init(indexes, elts)
+	if err != nil {
+		return nil, err
+	}
+
+	return ca, nil
+}
+
+// Init initializes a compacted array from the slice type elts
+// the indexes parameter must be a ascending array of type unit32,
+// otherwise, return the ErrIndexNotAscending error
+func (a *RoaringArray) Init(indexes []uint32, elts interface{}) error {
+
+	rElts := reflect.ValueOf(elts)
+	if rElts.Kind() != reflect.Slice {
+		panic("input is not a slice")
+	}
+
+	nElts := rElts.Len()
+
+	if len(indexes) != nElts {
+		return ErrIndexLen
+	}
+
+	err := a.InitIndex(indexes)
+	if err != nil {
+		return err
+	}
+
+	st := newEltStore(a.Converter, rElts)
+	a.Elts, a.EltOffsets = st.elts, st.eltOffsets
+
+	return nil
+}
+
+// Get returns the value indexed by idx if it is in array, else return nil
+func (a *RoaringArray) Get(idx uint32) interface{} {
+	v, _ := a.Get2(idx)
+	return v
+}
+
+// Get2 returns the value indexed by `idx` and a bool indicating existence.
+// If `idx` does not present it returns `nil, false`.
+func (a *RoaringArray) Get2(idx uint32) (interface{}, bool) {
+	pos, ok := a.GetEltIndex(idx)
+	if !ok {
+		return nil, false
+	}
+
+	return a.store().eltAt(pos), true
+}
+
+// GetBytes is similar to Get2 but does not return the byte slice instead of
+// unmarshaled data.
+// `eltsize` is ignored if elts are var-length.
+//
+// It does not apply to an EltsConverter, with which an elt may not take whole
+// bytes.
+func (a *RoaringArray) GetBytes(idx uint32, eltsize int) ([]byte, bool) {
+	dataIndex, ok := a.GetEltIndex(idx)
+	if !ok {
+		return nil, false
+	}
+
+	return a.store().eltBytes(dataIndex, eltsize), true
+}
+
+// store returns the elts part of the array.
+func (a *RoaringArray) store() eltStore {
+	return eltStore{
+		conv:       a.Converter,
+		cnt:        a.Cnt,
+		elts:       a.Elts,
+		eltOffsets: a.EltOffsets,
+	}
+}
+
+// GetVersion returns a Version to identify this data type: "r32"
+func (a *RoaringArray) GetVersion() version.Version {
+	return "r32"
+}
@@ -0,0 +1,202 @@
+package array
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/marshal"
+	"github.com/openacid/slim/serialize"
+)
+
+func TestRoaringArray(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+	m := map[uint32]bool{}
+
+	// chunk 0: sparse, list
+	for _, i := range []uint32{0, 1, 5, 63, 64, 1000, 65535} {
+		m[i] = true
+	}
+
+	// chunk 1: dense and random, bitmap
+	for i := 0; i < 30000; i++ {
+		m[1<<16+uint32(rnd.Intn(1<<16))] = true
+	}
+
+	// chunk 2: runs
+	for i := uint32(0); i < 5000; i++ {
+		m[2<<16+i] = true
+		m[2<<16+20000+i] = true
+	}
+	m[2<<16+65535] = true
+
+	// chunk 3: full
+	for i := uint32(0); i < 1<<16; i++ {
+		m[3<<16+i] = true
+	}
+
+	// far away
+	m[4000000000] = true
+	m[0xffffffff] = true
+
+	index := []uint32{}
+	for i := range m {
+		index = append(index, i)
+	}
+	sort.Slice(index, func(i, j int) bool { return index[i] < index[j] })
+
+	eltsData := []uint32{}
+	present := map[uint32]uint32{}
+	for i, idx := range index {
+		eltsData = append(eltsData, uint32(i)*3)
+		present[idx] = uint32(i) * 3
+	}
+
+	ca, err := NewRoaring(U32Conv{}, index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	if ca.Cnt != uint32(len(index)) {
+		t.Fatalf("cnt is not equal expect: %d, act: %d", len(index), ca.Cnt)
+	}
+
+	wantKeys := []uint32{0, 1, 2, 3, 4000000000 >> 16, 0xffff}
+	wantKinds := []uint32{containerList, containerBitmap, containerRun, containerRun, containerList, containerList}
+	if !reflect.DeepEqual(wantKeys, ca.Keys) || !reflect.DeepEqual(wantKinds, ca.Kinds) {
+		t.Fatalf("expect keys: %v kinds: %v; but: %v %v", wantKeys, wantKinds, ca.Keys, ca.Kinds)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, ca); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &RoaringArray{Converter: U32Conv{}}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	if loaded.GetVersion() != "r32" {
+		t.Fatalf("version should be r32")
+	}
+
+	// every index in the first chunks and around present indexes
+
+	probes := []uint32{}
+	for i := uint32(0); i < 5<<16; i++ {
+		probes = append(probes, i)
+	}
+	for _, idx := range index {
+		probes = append(probes, idx-1, idx, idx+1)
+	}
+
+	for _, a := range []*RoaringArray{ca, loaded} {
+		for _, idx := range probes {
+			want, wantfound := present[idx]
+			v, found := a.Get2(idx)
+			if found != wantfound || a.Has(idx) != wantfound {
+				t.Fatalf("Get2 i:%d expect found: %v, act: %v", idx, wantfound, found)
+			}
+			if found && v.(uint32) != want {
+				t.Fatalf("Get2 i:%d expect: %d, act: %d", idx, want, v)
+			}
+		}
+	}
+}
+
+func TestRoaringSpace(t *testing.T) {
+
+	ca, err := NewRoaring(U16Conv{}, []uint32{4000000000}, []uint16{1})
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	if v := ca.Get(4000000000); v.(uint16) != 1 {
+		t.Fatalf("expect 1 but: %v", v)
+	}
+
+	size := proto.Size(ca)
+	if size > 64 {
+		t.Fatalf("expect a single index takes a few bytes but: %d", size)
+	}
+}
+
+var OutputRoaring uint32
+
+func BenchmarkRoaringGetEltIndex(b *testing.B) {
+
+	index := makeIndexes(5<<16, 0.3)
+
+	ra := &RoaringIndex{}
+	ra.InitIndex(index)
+
+	b.ResetTimer()
+
+	var s uint32
+	for i := 0; i < b.N; i++ {
+		r, _ := ra.GetEltIndex(index[i%len(index)])
+		s += r
+	}
+	OutputRoaring = s
+}
+
+func BenchmarkArray32GetEltIndex(b *testing.B) {
+
+	index := makeIndexes(5<<16, 0.3)
+
+	a := &Array32Index{}
+	a.InitIndexBitmap(index)
+
+	b.ResetTimer()
+
+	var s uint32
+	for i := 0; i < b.N; i++ {
+		r, _ := a.GetEltIndex(index[i%len(index)])
+		s += r
+	}
+	OutputRoaring = s
+}
+
+func TestRoaringVarLength(t *testing.T) {
+
+	index := makeIndexes(4<<16, 0.3)
+	elts := make([]string, len(index))
+	for i := range elts {
+		elts[i] = strings.Repeat("x", i%7)
+	}
+
+	for _, conv := range []Converter{marshal.String16{}, DictConv{Converter: marshal.String16{}}} {
+
+		ca, err := NewRoaring(conv, index, elts)
+		if err != nil {
+			t.Fatalf("%T failed new roaring array, err: %s", conv, err)
+		}
+
+		buf := new(bytes.Buffer)
+		if _, err := serialize.Marshal(buf, ca); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		loaded := &RoaringArray{Converter: conv}
+		if err := serialize.Unmarshal(buf, loaded); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for _, a := range []*RoaringArray{ca, loaded} {
+			for i, idx := range index {
+				v, found := a.Get2(idx)
+				if !found || v.(string) != elts[i] {
+					t.Fatalf("%T Get2 i:%d expect: %q, act: %v %v", conv, idx, elts[i], v, found)
+				}
+			}
+			if v, found := a.Get2(4 << 16); found {
+				t.Fatalf("%T Get2 i:%d expect not found but: %v", conv, 4<<16, v)
+			}
+		}
+	}
+}
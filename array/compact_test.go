@@ -6,28 +6,51 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
 	proto "github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/marshal"
 )
 
-func TestNewErrorArgments(t *testing.T) {
-	var index []uint32
+func TestNewErrorArguments(t *testing.T) {
+
 	eltsData := []uint32{12, 15, 19, 120, 300}
 
-	var err error
+	errOf := func(_ interface{}, err error) error { return err }
+
+	cases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"U32 index length", errOf(NewU32([]uint32{1, 5, 9, 203}, eltsData)), ErrIndexLen},
+		{"U32 unsorted", errOf(NewU32([]uint32{1, 5, 5, 203, 400}, eltsData)), ErrIndexNotAscending},
+
+		{"64 index length", errOf(New64(U32Conv{}, []uint64{1, 5, 9, 1 << 40}, eltsData)), ErrIndexLen},
+		{"64 unsorted", errOf(New64(U32Conv{}, []uint64{1, 5, 5, 1 << 40, 1 << 50}, eltsData)), ErrIndexNotAscending},
 
-	index = []uint32{1, 5, 9, 203}
-	_, err = NewU32(index, eltsData)
-	if err == nil {
-		t.Fatalf("new with wrong index length must error")
+		{"roaring index length", errOf(NewRoaring(U32Conv{}, []uint32{1, 5, 9, 203}, eltsData)), ErrIndexLen},
+		{"roaring unsorted", errOf(NewRoaring(U32Conv{}, []uint32{1, 5, 5, 203, 400}, eltsData)), ErrIndexNotAscending},
+		{"roaring unsorted chunk", errOf(NewRoaring(U32Conv{}, []uint32{1, 5, 1 << 20, 203, 400}, eltsData)), ErrIndexNotAscending},
+
+		{"packed width 0", errOf(NewPacked(0, nil)), ErrPackedWidth},
+		{"packed width 65", errOf(NewPacked(65, nil)), ErrPackedWidth},
+		{"packed overflow", errOf(NewPacked(3, []uint64{1, 7, 8})), ErrPackedOverflow},
+
+		{"eliasfano not monotone", errOf(NewEliasFano([]uint64{1, 5, 3})), ErrNotMonotone},
+
+		{"columns count", newTestColumns().Init([]uint32{1, 5}, []uint32{1, 2}, []uint64{1, 2}), ErrColumnsLen},
+		{"columns index length", newTestColumns().Init([]uint32{1, 5}, []uint32{1, 2}, []uint64{1, 2}, []string{"a"}), ErrIndexLen},
+		{"columns unsorted", newTestColumns().Init([]uint32{5, 1}, []uint32{1, 2}, []uint64{1, 2}, []string{"a", "b"}), ErrIndexNotAscending},
 	}
 
-	index = []uint32{1, 5, 5, 203, 400}
-	_, err = NewU32(index, eltsData)
-	if err == nil {
-		t.Fatalf("new with unsorted index must error")
+	for _, c := range cases {
+		if c.err != c.want {
+			t.Fatalf("%s: expect error: %v but: %v", c.name, c.want, c.err)
+		}
 	}
 }
 
@@ -141,6 +164,8 @@ func TestSerialize(t *testing.T) {
 		0x22, 0x10, 0xc, 0x0, 0x0, 0x0, 0xf, 0x0,
 		0x0, 0x0, 0x13, 0x0, 0x0, 0x0, 0x78, 0x0,
 		0x0, 0x0,
+		// ConverterName: "u32"
+		0x32, 0x3, 0x75, 0x33, 0x32,
 	}
 
 	index := []uint32{1, 5, 9, 203}
@@ -182,3 +207,626 @@ func TestSerialize(t *testing.T) {
 		t.Fatalf("second serialized data incorrect")
 	}
 }
+
+func TestSetDelete(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	model := map[uint32]uint32{}
+	index, eltsData := []uint32{}, []uint32{}
+	for i := uint32(0); i < 2000; i += uint32(1 + rnd.Intn(5)) {
+		index = append(index, i)
+		eltsData = append(eltsData, i*10)
+		model[i] = i * 10
+	}
+
+	ca, err := NewU32(index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	check := func() {
+		if ca.Cnt != uint32(len(model)) {
+			t.Fatalf("cnt is not equal expect: %d, act: %d", len(model), ca.Cnt)
+		}
+		for i := uint32(0); i < 3000; i++ {
+			want, wantfound := model[i]
+			v, found := ca.Get2(i)
+			if found != wantfound {
+				t.Fatalf("Get2 i:%d expect found: %v, act: %v", i, wantfound, found)
+			}
+			if found && v.(uint32) != want {
+				t.Fatalf("Get2 i:%d expect: %d, act: %d", i, want, v)
+			}
+		}
+	}
+
+	for round := 0; round < 10; round++ {
+		for j := 0; j < 100; j++ {
+			idx := uint32(rnd.Intn(2500))
+			switch rnd.Intn(3) {
+			case 0:
+				_, present := model[idx]
+				if ca.Delete(idx) != present {
+					t.Fatalf("Delete i:%d expect: %v", idx, present)
+				}
+				delete(model, idx)
+			default:
+				v := rnd.Uint32()
+				ca.Set(idx, v)
+				model[idx] = v
+			}
+		}
+		check()
+	}
+
+	// serialized array is the same as the one built from scratch
+
+	b, err := proto.Marshal(ca)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if proto.Size(ca) != len(b) {
+		t.Fatalf("expect size: %d but: %d", len(b), proto.Size(ca))
+	}
+
+	loaded := &Array32{Converter: U32Conv{}}
+	if err := proto.Unmarshal(b, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	ca = loaded
+	check()
+}
+
+func TestVarLength(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+	randStr := func() string {
+		b := make([]byte, rnd.Intn(40))
+		rnd.Read(b)
+		return string(b)
+	}
+
+	model := map[uint32]string{}
+	index, eltsData := []uint32{}, []string{}
+	for i := uint32(0); i < 2000; i += uint32(1 + rnd.Intn(5)) {
+		v := randStr()
+		index = append(index, i)
+		eltsData = append(eltsData, v)
+		model[i] = v
+	}
+
+	ca, err := New(marshal.String16{}, index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+	if len(ca.EltOffsets) != (len(index)+eltOffsetSample-1)/eltOffsetSample {
+		t.Fatalf("expect %d elt offsets but: %d", (len(index)+eltOffsetSample-1)/eltOffsetSample, len(ca.EltOffsets))
+	}
+
+	check := func(ca *Array32) {
+		for i := uint32(0); i < 3000; i++ {
+			want, wantfound := model[i]
+			v, found := ca.Get2(i)
+			if found != wantfound {
+				t.Fatalf("Get2 i:%d expect found: %v, act: %v", i, wantfound, found)
+			}
+			if found && v.(string) != want {
+				t.Fatalf("Get2 i:%d expect: %q, act: %q", i, want, v)
+			}
+		}
+
+		n := 0
+		ca.Range(func(idx uint32, v interface{}) bool {
+			if v.(string) != model[idx] {
+				t.Fatalf("Range i:%d expect: %q, act: %q", idx, model[idx], v)
+			}
+			n++
+			return true
+		})
+		if n != len(model) {
+			t.Fatalf("Range expect %d elts but: %d", len(model), n)
+		}
+	}
+
+	check(ca)
+
+	for j := 0; j < 500; j++ {
+		idx := uint32(rnd.Intn(2500))
+		if rnd.Intn(3) == 0 {
+			_, present := model[idx]
+			if ca.Delete(idx) != present {
+				t.Fatalf("Delete i:%d expect: %v", idx, present)
+			}
+			delete(model, idx)
+		} else {
+			v := randStr()
+			ca.Set(idx, v)
+			model[idx] = v
+		}
+	}
+	check(ca)
+
+	b, err := proto.Marshal(ca)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	loaded := &Array32{Converter: marshal.String16{}}
+	if err := proto.Unmarshal(b, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	check(loaded)
+
+	sub := &Array32Index{}
+	if err := sub.InitIndexBitmap([]uint32{3, 500, 1999, 2400}); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	pr := ca.Project(sub)
+	pr.Range(func(idx uint32, v interface{}) bool {
+		if v.(string) != model[idx] {
+			t.Fatalf("Project i:%d expect: %q, act: %q", idx, model[idx], v)
+		}
+		if v2, _ := pr.Get2(idx); v2 != v {
+			t.Fatalf("Project Get2 i:%d expect: %q, act: %q", idx, v, v2)
+		}
+		return true
+	})
+}
+
+func TestVarLengthSameSize(t *testing.T) {
+
+	ca, err := New(marshal.String16{}, []uint32{1, 5, 9}, []string{"ab", "cd", "ef"})
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+	if len(ca.EltOffsets) != 0 {
+		t.Fatalf("expect no elt offsets for same size elts but: %v", ca.EltOffsets)
+	}
+
+	if v := ca.Get(5); v.(string) != "cd" {
+		t.Fatalf("expect cd but: %v", v)
+	}
+
+	// a longer elt switches to var-length
+	ca.Set(5, "hello")
+	ca.Set(7, "")
+	if len(ca.EltOffsets) == 0 {
+		t.Fatalf("expect elt offsets after setting a longer elt")
+	}
+
+	for idx, want := range map[uint32]string{1: "ab", 5: "hello", 7: "", 9: "ef"} {
+		if v := ca.Get(idx); v.(string) != want {
+			t.Fatalf("Get i:%d expect: %q but: %v", idx, want, v)
+		}
+	}
+}
+
+func TestSetDeleteLazyOffsets(t *testing.T) {
+
+	ca, err := NewU32([]uint32{1, 100, 200, 300}, []uint32{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	ca.Set(2, uint32(5))
+	if !reflect.DeepEqual(ca.pending, []offsetDelta{{word: 1, sum: 1}}) {
+		t.Fatalf("expect a pending delta of words after word 0 but: %v", ca.pending)
+	}
+
+	// lookups apply pending deltas without writing Offsets
+	if v := ca.Get(100); v.(uint32) != 2 {
+		t.Fatalf("expect 2 but: %v", v)
+	}
+	if v := ca.Get(300); v.(uint32) != 4 {
+		t.Fatalf("expect 4 but: %v", v)
+	}
+	if len(ca.pending) != 1 || !reflect.DeepEqual(ca.Offsets, []uint32{0, 1, 0, 2, 3}) {
+		t.Fatalf("expect Offsets not updated but: %v %v", ca.pending, ca.Offsets)
+	}
+
+	// marshaling does not update Offsets either
+	b, err := proto.Marshal(ca)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if len(ca.pending) != 1 {
+		t.Fatalf("expect pending deltas after marshaling")
+	}
+	loaded := &Array32{}
+	if err := proto.Unmarshal(b, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(loaded.Offsets, []uint32{0, 2, 0, 3, 4}) {
+		t.Fatalf("expect updated Offsets marshaled but: %v", loaded.Offsets)
+	}
+
+	ca.RebuildOffsets()
+	if len(ca.pending) != 0 {
+		t.Fatalf("expect no pending deltas")
+	}
+	if !reflect.DeepEqual(ca.Offsets, loaded.Offsets) {
+		t.Fatalf("expect Offsets %v but: %v", loaded.Offsets, ca.Offsets)
+	}
+
+	// insert after the last word and delete it
+
+	ca.Set(1000, uint32(6))
+	if v := ca.Get(1000); v.(uint32) != 6 {
+		t.Fatalf("expect 6 but: %v", v)
+	}
+	if !ca.Delete(1000) || ca.Delete(1000) {
+		t.Fatalf("expect to delete 1000 once")
+	}
+	if len(ca.Bitmaps) != 5 {
+		t.Fatalf("expect trailing empty words removed but: %d", len(ca.Bitmaps))
+	}
+
+	want := []uint32{1, 5, 2, 3, 4}
+	for i, idx := range []uint32{1, 2, 100, 200, 300} {
+		if v := ca.Get(idx); v.(uint32) != want[i] {
+			t.Fatalf("Get i:%d expect: %d but: %v", idx, want[i], v)
+		}
+	}
+}
+
+func TestSetDeleteRandom(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(0))
+
+	ca, err := NewU32(nil, nil)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	model := map[uint32]uint32{}
+	for i := 0; i < 3000; i++ {
+
+		// alternate low and high indexes
+		idx := uint32(rnd.Intn(64 * 200))
+		if i%2 == 1 {
+			idx = uint32(rnd.Intn(64 * 4))
+		}
+
+		if rnd.Intn(3) == 0 {
+			_, present := model[idx]
+			if ca.Delete(idx) != present {
+				t.Fatalf("%d-th: Delete %d expect: %v", i, idx, present)
+			}
+			delete(model, idx)
+		} else {
+			ca.Set(idx, uint32(i))
+			model[idx] = uint32(i)
+		}
+
+		if len(ca.pending) >= maxPendingDeltas {
+			t.Fatalf("%d-th: expect less than %d pending deltas but: %d", i, maxPendingDeltas, len(ca.pending))
+		}
+
+		if i%100 != 0 {
+			continue
+		}
+
+		for j := uint32(0); j < 64*200; j++ {
+			v, found := ca.Get2(j)
+			want, wantfound := model[j]
+			if found != wantfound || (found && v.(uint32) != want) {
+				t.Fatalf("%d-th: Get2 %d expect: %v %v but: %v %v", i, j, want, wantfound, v, found)
+			}
+		}
+	}
+
+	// Offsets are the same as a newly built array after applying deltas.
+
+	index := []uint32{}
+	for idx := range model {
+		index = append(index, idx)
+	}
+	sort.Slice(index, func(i, j int) bool { return index[i] < index[j] })
+	elts := []uint32{}
+	for _, idx := range index {
+		elts = append(elts, model[idx])
+	}
+
+	built, err := NewU32(index, elts)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	ca.RebuildOffsets()
+	if !reflect.DeepEqual(built.Offsets, ca.Offsets) || !reflect.DeepEqual(built.Bitmaps, ca.Bitmaps) {
+		t.Fatalf("expect the same index as a newly built one")
+	}
+}
+
+func TestSetEmptyWords(t *testing.T) {
+
+	ca, err := NewU32([]uint32{64 * 1000}, []uint32{1000})
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	want := map[uint32]uint32{64 * 1000: 1000}
+	check := func() {
+		for idx, v := range want {
+			if got := ca.Get(idx); got.(uint32) != v {
+				t.Fatalf("Get i:%d expect: %d but: %v", idx, v, got)
+			}
+		}
+		if int(ca.Cnt) != len(want) {
+			t.Fatalf("expect cnt: %d but: %d", len(want), ca.Cnt)
+		}
+	}
+
+	// set into empty words, before, between and after present ones
+	for _, w := range []uint32{500, 1, 999, 1200, 700, 0, 1100} {
+		ca.Set(w*64+3, w)
+		want[w*64+3] = w
+		check()
+	}
+
+	if len(ca.wordOffsets) != len(ca.Bitmaps) {
+		t.Fatalf("expect offsets of all words: %d but: %d", len(ca.Bitmaps), len(ca.wordOffsets))
+	}
+	for i, w := range ca.Bitmaps {
+		if w == 0 && ca.Offsets[i] != 0 {
+			t.Fatalf("expect Offsets of empty word %d to be 0 but: %d", i, ca.Offsets[i])
+		}
+	}
+
+	ca.Delete(500*64 + 3)
+	delete(want, 500*64+3)
+	ca.Delete(1200*64 + 3)
+	delete(want, 1200*64+3)
+	ca.Set(600*64, uint32(600))
+	want[600*64] = 600
+	check()
+
+	// a reloaded array rebuilds offsets of empty words
+	b, err := proto.Marshal(ca)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if err := proto.Unmarshal(b, ca); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if ca.wordOffsets != nil {
+		t.Fatalf("expect offsets of all words reset after loading")
+	}
+	ca.Set(800*64, uint32(800))
+	want[800*64] = 800
+	check()
+}
+
+func TestSetConcurrentGet(t *testing.T) {
+
+	index := []uint32{}
+	elts := []uint32{}
+	for i := uint32(0); i < 1000; i++ {
+		index = append(index, i*3)
+		elts = append(elts, i)
+	}
+
+	ca, err := NewU32(index, elts)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	// stale Offsets after word 0
+	ca.Set(1, uint32(1000))
+	ca.Delete(1)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, idx := range index {
+				if v := ca.Get(idx); v.(uint32) != elts[i] {
+					t.Errorf("Get i:%d expect: %d but: %v", idx, elts[i], v)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRange(t *testing.T) {
+
+	index := []uint32{0, 1, 63, 64, 200, 1000, 1001, 5000}
+	eltsData := []uint32{}
+	for _, idx := range index {
+		eltsData = append(eltsData, idx*2)
+	}
+
+	ca, err := NewU32(index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	// updates do not affect Range
+	ca.Set(65, uint32(130))
+	ca.Delete(1000)
+	index = []uint32{0, 1, 63, 64, 65, 200, 1001, 5000}
+
+	gotIdx := []uint32{}
+	ca.Range(func(idx uint32, v interface{}) bool {
+		if v.(uint32) != idx*2 {
+			t.Fatalf("Range i:%d expect: %d, act: %v", idx, idx*2, v)
+		}
+		gotIdx = append(gotIdx, idx)
+		return true
+	})
+	if !reflect.DeepEqual(index, gotIdx) {
+		t.Fatalf("Range expect: %v, act: %v", index, gotIdx)
+	}
+
+	// stop early
+	gotIdx = []uint32{}
+	ca.Range(func(idx uint32, v interface{}) bool {
+		gotIdx = append(gotIdx, idx)
+		return len(gotIdx) < 3
+	})
+	if !reflect.DeepEqual(index[:3], gotIdx) {
+		t.Fatalf("Range expect: %v, act: %v", index[:3], gotIdx)
+	}
+
+	empty, _ := NewU32([]uint32{}, []uint32{})
+	empty.Range(func(idx uint32, v interface{}) bool {
+		t.Fatalf("expect no elt but: %d", idx)
+		return true
+	})
+}
+
+func TestNextPrevIndex(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	index := []uint32{}
+	for i := uint32(0); i < 3000; i += uint32(1 + rnd.Intn(200)) {
+		index = append(index, i)
+	}
+
+	ca, err := NewU32(index, make([]uint32, len(index)))
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	for i := uint32(0); i < 3300; i++ {
+
+		wantNext, wantNextFound := uint32(0), false
+		wantPrev, wantPrevFound := uint32(0), false
+		for _, idx := range index {
+			if idx >= i && !wantNextFound {
+				wantNext, wantNextFound = idx, true
+			}
+			if idx <= i {
+				wantPrev, wantPrevFound = idx, true
+			}
+		}
+
+		next, found := ca.NextIndex(i)
+		if next != wantNext || found != wantNextFound {
+			t.Fatalf("NextIndex i:%d expect: %d %v, act: %d %v", i, wantNext, wantNextFound, next, found)
+		}
+
+		prev, found := ca.PrevIndex(i)
+		if prev != wantPrev || found != wantPrevFound {
+			t.Fatalf("PrevIndex i:%d expect: %d %v, act: %d %v", i, wantPrev, wantPrevFound, prev, found)
+		}
+	}
+
+	empty, _ := NewU32([]uint32{}, []uint32{})
+	if _, found := empty.NextIndex(0); found {
+		t.Fatalf("expect no next index")
+	}
+	if _, found := empty.PrevIndex(100); found {
+		t.Fatalf("expect no prev index")
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	randIndex := func(n, max int) []uint32 {
+		m := map[uint32]bool{}
+		for i := 0; i < n; i++ {
+			m[uint32(rnd.Intn(max))] = true
+		}
+		index := []uint32{}
+		for idx := range m {
+			index = append(index, idx)
+		}
+		sort.Slice(index, func(i, j int) bool { return index[i] < index[j] })
+		return index
+	}
+
+	// build returns indexes in `x` that `pick` returns true for.
+	build := func(x []uint32, pick func(idx uint32) bool) *Array32Index {
+		index := []uint32{}
+		for _, idx := range x {
+			if pick(idx) {
+				index = append(index, idx)
+			}
+		}
+		a := &Array32Index{}
+		a.InitIndexBitmap(index)
+		return a
+	}
+
+	cases := [][2][]uint32{
+		{randIndex(300, 2000), randIndex(300, 2000)},
+		{randIndex(300, 2000), randIndex(50, 200)},
+		{randIndex(50, 200), randIndex(300, 2000)},
+		{randIndex(50, 200), {}},
+		{{}, {}},
+	}
+
+	for i, c := range cases {
+		a, b := build(c[0], func(uint32) bool { return true }), build(c[1], func(uint32) bool { return true })
+
+		all := append(append([]uint32{}, c[0]...), c[1]...)
+		sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+		dedup := []uint32{}
+		for j, idx := range all {
+			if j == 0 || all[j-1] != idx {
+				dedup = append(dedup, idx)
+			}
+		}
+
+		wantUnion := build(dedup, func(uint32) bool { return true })
+		wantIntersect := build(c[0], b.Has)
+		wantDiff := build(c[0], func(idx uint32) bool { return !b.Has(idx) })
+
+		for _, r := range []struct {
+			name      string
+			want, got *Array32Index
+		}{
+			{"Union", wantUnion, a.Union(b)},
+			{"Intersect", wantIntersect, a.Intersect(b)},
+			{"Difference", wantDiff, a.Difference(b)},
+		} {
+			if r.got.Cnt != r.want.Cnt ||
+				!reflect.DeepEqual(r.want.Bitmaps, r.got.Bitmaps) ||
+				!reflect.DeepEqual(r.want.Offsets, r.got.Offsets) {
+				t.Fatalf("%d-th: %s expect: %v, act: %v", i+1, r.name, r.want, r.got)
+			}
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+
+	index := []uint32{1, 5, 64, 100, 200, 1000}
+	eltsData := []uint32{10, 50, 640, 1000, 2000, 10000}
+
+	ca, err := NewU32(index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	sub := &Array32Index{}
+	sub.InitIndexBitmap([]uint32{0, 5, 100, 101, 1000, 5000})
+
+	p := ca.Project(sub)
+
+	want, _ := NewU32([]uint32{5, 100, 1000}, []uint32{50, 1000, 10000})
+	if p.Cnt != want.Cnt ||
+		!reflect.DeepEqual(want.Bitmaps, p.Bitmaps) ||
+		!reflect.DeepEqual(want.Offsets, p.Offsets) ||
+		!reflect.DeepEqual(want.Elts, p.Elts) {
+		t.Fatalf("Project expect: %v, act: %v", want, p)
+	}
+
+	for _, idx := range []uint32{5, 100, 1000} {
+		if p.Get(idx) != ca.Get(idx) {
+			t.Fatalf("Get i:%d expect: %v, act: %v", idx, ca.Get(idx), p.Get(idx))
+		}
+	}
+
+	empty := ca.Project(&Array32Index{})
+	if empty.Cnt != 0 || len(empty.Elts) != 0 || empty.Get(5) != nil {
+		t.Fatalf("expect empty projection but: %v", empty)
+	}
+}
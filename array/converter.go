@@ -19,6 +19,21 @@ type Converter interface {
 	GetMarshaledSize([]byte) int
 }
 
+// An EltsConverter is a Converter that converts all elements of an array
+// together, e.g., bit-packs them, instead of one by one.
+//
+// Array32 uses MarshalElts and UnmarshalElt instead of Marshal and Unmarshal if
+// its Converter is an EltsConverter.
+type EltsConverter interface {
+	Converter
+
+	// Convert all elements into serialised byte stream.
+	MarshalElts([]interface{}) []byte
+
+	// Read the i-th element from byte stream built by MarshalElts.
+	UnmarshalElt([]byte, uint32) interface{}
+}
+
 // U16Conv converts uint16 to slice of 2 bytes and back.
 type U16Conv struct{}
 
@@ -0,0 +1,99 @@
+package array
+
+import (
+	"encoding/binary"
+	mbits "math/bits"
+)
+
+// dictHeaderSize is the size of the header of elts encoded by DictConv:
+// number of distinct values, code bit width and size of codes, 4 bytes each.
+const dictHeaderSize = 12
+
+// NewDict creates a Array32 with dictionary-encoded elts converted by `conv`.
+func NewDict(conv Converter, indexes []uint32, elts interface{}) (*Array32, error) {
+	return New(DictConv{Converter: conv}, indexes, elts)
+}
+
+// DictConv is an EltsConverter that stores every distinct value once, for elts
+// that repeat a lot, such as tier ids or shard names.
+//
+// The embedded Converter converts a distinct value, which may be var-length.
+// Every elt is a bit-packed code, the position of its value in the dictionary.
+// Two elts are the same value if they are marshaled to the same bytes.
+//
+// Elts are encoded as:
+//
+//	header:  number of distinct values, code width, size of codes
+//	codes:   bit-packed code of every elt, in the layout of PackedArray.Words
+//	offsets: offset of every distinct value, 4 bytes each
+//	values:  marshaled distinct values
+//
+// Thus an elt is located in O(1) time.
+//
+// Used as a plain Converter, it converts one elt with the embedded Converter.
+type DictConv struct {
+	Converter
+}
+
+// MarshalElts builds a dictionary of distinct values in the order they first
+// appear and encodes every elt as a code.
+func (c DictConv) MarshalElts(elts []interface{}) []byte {
+
+	codes := make([]uint64, len(elts))
+	codeOf := map[string]uint64{}
+	values := []byte{}
+	offsets := []uint32{}
+
+	for i, e := range elts {
+		raw := c.Marshal(e)
+
+		code, ok := codeOf[string(raw)]
+		if !ok {
+			code = uint64(len(offsets))
+			codeOf[string(raw)] = code
+			offsets = append(offsets, uint32(len(values)))
+			values = append(values, raw...)
+		}
+		codes[i] = code
+	}
+
+	width := uint32(1)
+	if len(offsets) > 1 {
+		width = uint32(mbits.Len64(uint64(len(offsets) - 1)))
+	}
+
+	p, err := NewPacked(width, codes)
+	if err != nil {
+		panic(err)
+	}
+	packed := wordsToBytes(p.Words)
+
+	b := make([]byte, dictHeaderSize, dictHeaderSize+len(packed)+4*len(offsets)+len(values))
+	binary.LittleEndian.PutUint32(b[0:], uint32(len(offsets)))
+	binary.LittleEndian.PutUint32(b[4:], width)
+	binary.LittleEndian.PutUint32(b[8:], uint32(len(packed)))
+
+	b = append(b, packed...)
+	for _, o := range offsets {
+		b = append(b, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(b[len(b)-4:], o)
+	}
+	return append(b, values...)
+}
+
+// UnmarshalElt returns the `i`-th elt in `b` built by MarshalElts.
+func (c DictConv) UnmarshalElt(b []byte, i uint32) interface{} {
+
+	nValues := binary.LittleEndian.Uint32(b[0:])
+	width := binary.LittleEndian.Uint32(b[4:])
+	codesSize := binary.LittleEndian.Uint32(b[8:])
+
+	codes := b[dictHeaderSize : dictHeaderSize+codesSize]
+	code := uint32(getPackedBytes(codes, width, i))
+
+	offsets := b[dictHeaderSize+codesSize:]
+	off := binary.LittleEndian.Uint32(offsets[4*code:])
+
+	_, v := c.Unmarshal(offsets[4*nValues+off:])
+	return v
+}
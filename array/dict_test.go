@@ -0,0 +1,124 @@
+package array
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/openacid/slim/marshal"
+	"github.com/openacid/slim/serialize"
+)
+
+func TestDict(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	tiers := []string{"hot", "warm", "cold", "archive", ""}
+
+	index, eltsData := []uint32{}, []string{}
+	for i := uint32(0); i < 5000; i += uint32(1 + rnd.Intn(3)) {
+		index = append(index, i)
+		eltsData = append(eltsData, tiers[rnd.Intn(len(tiers))])
+	}
+
+	ca, err := NewDict(marshal.String16{}, index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	plain, err := New(marshal.String16{}, index, eltsData)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	// 3 bits for every elt
+	if len(ca.Elts) > len(index)*3/8+128 || len(ca.Elts)*4 > len(plain.Elts) {
+		t.Fatalf("expect dict elts much less than %d but: %d", len(plain.Elts), len(ca.Elts))
+	}
+
+	check := func(ca *Array32) {
+		for i, idx := range index {
+			v, found := ca.Get2(idx)
+			if !found || v.(string) != eltsData[i] {
+				t.Fatalf("Get2 i:%d expect: %q, act: %v %v", idx, eltsData[i], v, found)
+			}
+		}
+		if ca.Has(5000) || ca.Get(5000) != nil {
+			t.Fatalf("expect 5000 not in array")
+		}
+	}
+
+	check(ca)
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, ca); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	loaded := &Array32{Converter: DictConv{Converter: marshal.String16{}}}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	check(loaded)
+}
+
+func TestDictWidth(t *testing.T) {
+
+	cases := []struct {
+		distinct  int
+		wantWidth uint32
+	}{
+		{1, 1},
+		{2, 1},
+		{3, 2},
+		{256, 8},
+		{257, 9},
+	}
+
+	for _, c := range cases {
+		n := 1000
+		index := make([]uint32, n)
+		elts := make([]uint32, n)
+		for i := range index {
+			index[i] = uint32(i)
+			elts[i] = uint32(i % c.distinct * 7)
+		}
+
+		ca, err := NewDict(U32Conv{}, index, elts)
+		if err != nil {
+			t.Fatalf("failed new compacted array, err: %s", err)
+		}
+
+		width := uint32(ca.Elts[4])
+		if width != c.wantWidth {
+			t.Fatalf("%d distinct values expect width %d but: %d", c.distinct, c.wantWidth, width)
+		}
+
+		for i, idx := range index {
+			if v := ca.Get(idx); v.(uint32) != elts[i] {
+				t.Fatalf("Get i:%d expect: %d, act: %v", idx, elts[i], v)
+			}
+		}
+	}
+}
+
+func TestDictSetDelete(t *testing.T) {
+
+	ca, err := NewDict(U16Conv{}, []uint32{1, 5, 9}, []uint16{7, 7, 8})
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	ca.Set(3, uint16(9))
+	ca.Set(5, uint16(8))
+	ca.Delete(9)
+
+	got := ""
+	ca.Range(func(idx uint32, v interface{}) bool {
+		got += fmt.Sprintf("%d:%d,", idx, v)
+		return true
+	})
+	if got != "1:7,3:9,5:8," {
+		t.Fatalf("expect 1:7,3:9,5:8, but: %s", got)
+	}
+}
@@ -0,0 +1,216 @@
+package array
+
+import (
+	"errors"
+	mbits "math/bits"
+
+	"github.com/openacid/slim/prototype"
+)
+
+// ErrNotMonotone is returned if values to build an EliasFano are not
+// non-decreasing.
+var ErrNotMonotone = errors.New("values must be non-decreasing")
+
+// efSample is the interval of "1"s or "0"s in Highs between select hints.
+const efSample = 256
+
+// EliasFano stores a non-decreasing sequence of uint64 with Elias-Fano
+// encoding, e.g. file offsets or timestamps.
+//
+// For n values with the greatest one less than U, every value is split into
+// `LowBits = floor(log2(U/n))` lower bits and the upper bits.
+// The lower bits are bit-packed in Lows.
+// The upper bits are unary coded in Highs: the i-th value sets bit
+// `i + v>>LowBits`.
+// Thus a value takes about `2 + log2(U/n)` bits.
+//
+// Position of every 256-th "1" and "0" in Highs are stored as hints, to locate
+// the i-th value or the first value greater than or equal to x, by scanning
+// only a few words.
+type EliasFano struct {
+	prototype.EliasFanoStorage
+}
+
+// NewEliasFano creates an EliasFano from a non-decreasing slice of uint64.
+// Otherwise it returns ErrNotMonotone.
+func NewEliasFano(values []uint64) (*EliasFano, error) {
+
+	n := uint64(len(values))
+
+	ef := &EliasFano{}
+	ef.Cnt = uint32(n)
+	if n == 0 {
+		return ef, nil
+	}
+
+	for i := 1; i < len(values); i++ {
+		if values[i] < values[i-1] {
+			return nil, ErrNotMonotone
+		}
+	}
+
+	last := values[n-1]
+	if last/n > 0 {
+		ef.LowBits = uint32(mbits.Len64(last/n) - 1)
+	}
+
+	if ef.LowBits > 0 {
+		mask := widthMask(ef.LowBits)
+		lows := make([]uint64, n)
+		for i, v := range values {
+			lows[i] = v & mask
+		}
+
+		p, err := NewPacked(ef.LowBits, lows)
+		if err != nil {
+			return nil, err
+		}
+		ef.Lows = p.Words
+	}
+
+	nBits := n + last>>ef.LowBits + 1
+	ef.Highs = make([]uint64, (nBits+63)/64)
+
+	zeros := uint64(0)
+	for i, v := range values {
+		pos := uint64(i) + v>>ef.LowBits
+
+		// zeros before this "1"
+		for ; zeros < pos-uint64(i); zeros++ {
+			if zeros%efSample == 0 {
+				ef.Select0Hints = append(ef.Select0Hints, zeros+uint64(i))
+			}
+		}
+
+		if i%efSample == 0 {
+			ef.Select1Hints = append(ef.Select1Hints, pos)
+		}
+		ef.Highs[pos>>6] |= uint64(1) << (pos & 63)
+	}
+
+	return ef, nil
+}
+
+// Len returns the number of values.
+func (ef *EliasFano) Len() uint32 {
+	return ef.Cnt
+}
+
+// Get returns the `i`-th value.
+// `i` must be less than Len().
+func (ef *EliasFano) Get(i uint32) uint64 {
+	pos := ef.select1(i)
+	return ef.value(i, pos)
+}
+
+// NextGEQ returns the position and value of the first value greater than or
+// equal to `x`, and a bool indicating if there is such a value.
+func (ef *EliasFano) NextGEQ(x uint64) (uint32, uint64, bool) {
+
+	if ef.Cnt == 0 || ef.Get(ef.Cnt-1) < x {
+		return 0, 0, false
+	}
+
+	// values with upper bits greater than or equal to `hx` start right after
+	// the `hx-1`-th "0".
+	hx := x >> ef.LowBits
+	from := uint32(0)
+	if hx > 0 {
+		from = uint32(ef.select0(hx-1) + 1 - hx)
+	}
+
+	var idx uint32
+	var val uint64
+	ef.RangeFrom(from, func(i uint32, v uint64) bool {
+		idx, val = i, v
+		return v < x
+	})
+
+	return idx, val, true
+}
+
+// Range calls `fn` for every value in order until `fn` returns false.
+func (ef *EliasFano) Range(fn func(i uint32, v uint64) bool) {
+	ef.RangeFrom(0, fn)
+}
+
+// RangeFrom calls `fn` for every value from the `i`-th in order until `fn`
+// returns false.
+//
+// It decodes Highs sequentially, thus a value takes O(1) time.
+func (ef *EliasFano) RangeFrom(i uint32, fn func(i uint32, v uint64) bool) {
+
+	if i >= ef.Cnt {
+		return
+	}
+
+	pos := ef.select1(i)
+	iWord := pos >> 6
+	word := ef.Highs[iWord] &^ (uint64(1)<<(pos&63) - 1)
+
+	for i < ef.Cnt {
+		for word == 0 {
+			iWord++
+			word = ef.Highs[iWord]
+		}
+
+		pos = iWord<<6 + uint64(mbits.TrailingZeros64(word))
+		word &= word - 1
+
+		if !fn(i, ef.value(i, pos)) {
+			return
+		}
+		i++
+	}
+}
+
+// value returns the `i`-th value whose "1" in Highs is at `pos`.
+func (ef *EliasFano) value(i uint32, pos uint64) uint64 {
+	v := (pos - uint64(i)) << ef.LowBits
+	if ef.LowBits > 0 {
+		v |= getPacked(ef.LowBits, i, func(j uint64) uint64 { return ef.Lows[j] })
+	}
+	return v
+}
+
+// select1 returns the position of the `k`-th "1" in Highs.
+func (ef *EliasFano) select1(k uint32) uint64 {
+	h := k / efSample
+	return selectFrom(ef.Highs, ef.Select1Hints[h], uint64(k-h*efSample), false)
+}
+
+// select0 returns the position of the `k`-th "0" in Highs.
+func (ef *EliasFano) select0(k uint64) uint64 {
+	h := k / efSample
+	return selectFrom(ef.Highs, ef.Select0Hints[h], k-h*efSample, true)
+}
+
+// selectFrom returns the position of the `k`-th "1", or "0" if `flip` is true,
+// counting from the one at `pos`.
+func selectFrom(words []uint64, pos, k uint64, flip bool) uint64 {
+
+	iWord := pos >> 6
+	word := words[iWord]
+	if flip {
+		word = ^word
+	}
+	word &^= uint64(1)<<(pos&63) - 1
+
+	for {
+		c := uint64(mbits.OnesCount64(word))
+		if k < c {
+			break
+		}
+		k -= c
+		iWord++
+		word = words[iWord]
+		if flip {
+			word = ^word
+		}
+	}
+
+	for ; k > 0; k-- {
+		word &= word - 1
+	}
+	return iWord<<6 + uint64(mbits.TrailingZeros64(word))
+}
@@ -0,0 +1,131 @@
+package array
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/openacid/slim/serialize"
+)
+
+func TestEliasFano(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	// values are `n` random steps of at most `maxGap` followed by `tail`.
+	cases := []struct {
+		name   string
+		n      int
+		maxGap uint64
+		tail   []uint64
+	}{
+		{name: "empty"},
+		{name: "single", tail: []uint64{7}},
+		{name: "zeros", tail: []uint64{0, 0, 0, 0}},
+		{name: "duplicates", tail: []uint64{1, 1, 3, 3, 3, 9, 9}},
+		{name: "max", tail: []uint64{0, 1 << 40, math.MaxUint64}},
+		{name: "dense", n: 5000, maxGap: 1},
+		{name: "sparse", n: 5000, maxGap: 1 << 20},
+		{name: "clustered", n: 3000, maxGap: 2, tail: []uint64{1 << 50, 1<<50 + 1, 1 << 51}},
+	}
+
+	for _, c := range cases {
+
+		values := []uint64{}
+		v := uint64(0)
+		for i := 0; i < c.n; i++ {
+			v += uint64(rnd.Int63n(int64(c.maxGap) + 1))
+			values = append(values, v)
+		}
+		values = append(values, c.tail...)
+
+		ef, err := NewEliasFano(values)
+		if err != nil {
+			t.Fatalf("%s: expect no error but: %s", c.name, err)
+		}
+
+		buf := new(bytes.Buffer)
+		n, err := serialize.Marshal(buf, ef)
+		if err != nil {
+			t.Fatalf("%s: expect no error but: %s", c.name, err)
+		}
+		if n != serialize.GetMarshalSize(ef) {
+			t.Fatalf("%s: expect size: %d but: %d", c.name, serialize.GetMarshalSize(ef), n)
+		}
+
+		loaded := &EliasFano{}
+		if err := serialize.Unmarshal(buf, loaded); err != nil {
+			t.Fatalf("%s: expect no error but: %s", c.name, err)
+		}
+
+		probes := []uint64{0, math.MaxUint64}
+		for _, v := range values {
+			probes = append(probes, v-1, v, v+1)
+		}
+
+		for _, ef := range []*EliasFano{ef, loaded} {
+
+			if ef.Len() != uint32(len(values)) {
+				t.Fatalf("%s: expect len: %d but: %d", c.name, len(values), ef.Len())
+			}
+
+			for i, want := range values {
+				if v := ef.Get(uint32(i)); v != want {
+					t.Fatalf("%s: Get(%d) expect: %d but: %d", c.name, i, want, v)
+				}
+			}
+
+			i := 0
+			ef.Range(func(j uint32, v uint64) bool {
+				if j != uint32(i) || v != values[i] {
+					t.Fatalf("%s: Range expect: %d %d, act: %d %d", c.name, i, values[i], j, v)
+				}
+				i++
+				return true
+			})
+			if i != len(values) {
+				t.Fatalf("%s: Range expect %d values but: %d", c.name, len(values), i)
+			}
+
+			for _, x := range probes {
+				want := sort.Search(len(values), func(i int) bool { return values[i] >= x })
+
+				idx, v, found := ef.NextGEQ(x)
+				if found != (want < len(values)) {
+					t.Fatalf("%s: NextGEQ(%d) expect found: %v", c.name, x, want < len(values))
+				}
+				if found && (idx != uint32(want) || v != values[want]) {
+					t.Fatalf("%s: NextGEQ(%d) expect: %d %d but: %d %d", c.name, x, want, values[want], idx, v)
+				}
+			}
+		}
+	}
+}
+
+func TestEliasFanoSpace(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	n := 100000
+	values := make([]uint64, n)
+	v := uint64(0)
+	for i := range values {
+		v += uint64(rnd.Int63n(1<<16 + 1))
+		values[i] = v
+	}
+
+	ef, err := NewEliasFano(values)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	// 2 + log2(U/n) bits for every value, plus select hints
+	u := values[n-1]
+	want := 2 + math.Log2(float64(u)/float64(n))
+	got := float64(64*(len(ef.Lows)+len(ef.Highs)+len(ef.Select1Hints)+len(ef.Select0Hints))) / float64(n)
+	if got > want+1 {
+		t.Fatalf("expect about %.2f bits per value but: %.2f", want, got)
+	}
+}
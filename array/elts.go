@@ -0,0 +1,126 @@
+package array
+
+import (
+	"reflect"
+)
+
+// eltStore is the encoded elts of an array and the Converter of them.
+//
+// It is shared by Array32, Array64 and RoaringArray, which differ only in the
+// index that maps an index to a position in elts.
+//
+// Elts may be var-length, e.g. strings with marshal.String16.
+// In this case eltOffsets is the offset in elts of every 16-th elt.
+// An EltsConverter encodes all elts at once and eltOffsets is not used.
+type eltStore struct {
+	conv       Converter
+	cnt        uint32
+	elts       []byte
+	eltOffsets []uint32
+}
+
+// newEltStore encodes elts in slice `rElts` with `conv`.
+func newEltStore(conv Converter, rElts reflect.Value) eltStore {
+
+	nElts := rElts.Len()
+	s := eltStore{conv: conv, cnt: uint32(nElts)}
+
+	if ec, ok := conv.(EltsConverter); ok {
+		all := make([]interface{}, nElts)
+		for i := range all {
+			all[i] = rElts.Index(i).Interface()
+		}
+		s.elts = ec.MarshalElts(all)
+		return s
+	}
+
+	s.elts = make([]byte, 0, nElts)
+
+	varLen := false
+	for i := 0; i < nElts; i++ {
+		ee := rElts.Index(i).Interface()
+		raw := conv.Marshal(ee)
+		s.elts = append(s.elts, raw...)
+
+		varLen = varLen || len(raw)*(i+1) != len(s.elts)
+	}
+
+	if varLen {
+		s.initEltOffsets()
+	}
+	return s
+}
+
+// eltAt returns the elt at position `pos` in elts.
+func (s eltStore) eltAt(pos uint32) interface{} {
+	if ec, ok := s.conv.(EltsConverter); ok {
+		return ec.UnmarshalElt(s.elts, pos)
+	}
+
+	_, val := s.conv.Unmarshal(s.eltBytes(pos, s.fixedEltSize()))
+	return val
+}
+
+// eltBytes returns the bytes of the elt at position `pos` in elts.
+// `eltsize` is ignored if elts are var-length.
+func (s eltStore) eltBytes(pos uint32, eltsize int) []byte {
+	if s.isVarLen() {
+		st := s.eltOffset(pos)
+		return s.elts[st : st+uint32(s.conv.GetMarshaledSize(s.elts[st:]))]
+	}
+
+	stIdx := uint32(eltsize) * pos
+	return s.elts[stIdx : stIdx+uint32(eltsize)]
+}
+
+// isVarLen returns true if elts are var-length and eltOffsets is used.
+func (s eltStore) isVarLen() bool {
+	return len(s.eltOffsets) > 0
+}
+
+// fixedEltSize returns the size of every elt if they are fixed-length.
+func (s eltStore) fixedEltSize() int {
+	if s.cnt == 0 {
+		return 0
+	}
+	return len(s.elts) / int(s.cnt)
+}
+
+// eltOffset returns the offset in elts of the `i`-th elt.
+// Only eltOffsets sampled at or before `i` are used, thus it still works after
+// the elts from `i` on are modified.
+func (s eltStore) eltOffset(i uint32) uint32 {
+	j := i / eltOffsetSample
+	if j >= uint32(len(s.eltOffsets)) {
+		j = uint32(len(s.eltOffsets)) - 1
+	}
+
+	off := s.eltOffsets[j]
+	for k := j * eltOffsetSample; k < i; k++ {
+		off += uint32(s.conv.GetMarshaledSize(s.elts[off:]))
+	}
+	return off
+}
+
+// initEltOffsets builds eltOffsets for var-length elts.
+func (s *eltStore) initEltOffsets() {
+	s.eltOffsets = []uint32{0}
+	s.rebuildEltOffsets(0)
+}
+
+// rebuildEltOffsets rebuilds eltOffsets after the `i`-th elt.
+func (s *eltStore) rebuildEltOffsets(i uint32) {
+	j := i / eltOffsetSample
+	if j >= uint32(len(s.eltOffsets)) {
+		j = uint32(len(s.eltOffsets)) - 1
+	}
+	s.eltOffsets = s.eltOffsets[:j+1]
+
+	off := s.eltOffsets[j]
+	for k := j * eltOffsetSample; k < s.cnt; k++ {
+		if k%eltOffsetSample == 0 && k > j*eltOffsetSample {
+			s.eltOffsets = append(s.eltOffsets, off)
+		}
+		off += uint32(s.conv.GetMarshaledSize(s.elts[off:]))
+	}
+}
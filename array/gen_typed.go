@@ -0,0 +1,255 @@
+//go:build ignore
+// +build ignore
+
+// This program generates typed.go and typed_test.go: typed arrays of every
+// builtin fixed size type, without interface{} or reflection.
+//
+// Usage:
+//		go generate ./array
+
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"text/template"
+)
+
+type typeDef struct {
+	Name string // suffix of type name, e.g. U16 for ArrayU16
+	Type string // element type
+	Size int    // marshaled size in bytes
+
+	// Put and Load convert an elt to and from a uint of Size bytes.
+	Put  string
+	Load string
+
+	// Samples are test elts.
+	Samples string
+}
+
+var types = []typeDef{
+	{"U8", "uint8", 1, "b[0] = v", "b[0]", "1, 0, 255, 7"},
+	{"U16", "uint16", 2, "binary.LittleEndian.PutUint16(b, v)", "binary.LittleEndian.Uint16(b)", "12, 15, 19, 120"},
+	{"U32", "uint32", 4, "binary.LittleEndian.PutUint32(b, v)", "binary.LittleEndian.Uint32(b)", "1, 0, math.MaxUint32, 7"},
+	{"U64", "uint64", 8, "binary.LittleEndian.PutUint64(b, v)", "binary.LittleEndian.Uint64(b)", "1, 0, math.MaxUint64, 7"},
+	{"I8", "int8", 1, "b[0] = byte(v)", "int8(b[0])", "-1, 0, math.MaxInt8, math.MinInt8"},
+	{"I16", "int16", 2, "binary.LittleEndian.PutUint16(b, uint16(v))", "int16(binary.LittleEndian.Uint16(b))", "-1, 0, math.MaxInt16, math.MinInt16"},
+	{"I32", "int32", 4, "binary.LittleEndian.PutUint32(b, uint32(v))", "int32(binary.LittleEndian.Uint32(b))", "-1, 0, math.MaxInt32, math.MinInt32"},
+	{"I64", "int64", 8, "binary.LittleEndian.PutUint64(b, uint64(v))", "int64(binary.LittleEndian.Uint64(b))", "-1, 0, math.MaxInt64, math.MinInt64"},
+	{"F32", "float32", 4, "binary.LittleEndian.PutUint32(b, math.Float32bits(v))", "math.Float32frombits(binary.LittleEndian.Uint32(b))", "-1.5, 0, math.MaxFloat32, math.SmallestNonzeroFloat32"},
+	{"F64", "float64", 8, "binary.LittleEndian.PutUint64(b, math.Float64bits(v))", "math.Float64frombits(binary.LittleEndian.Uint64(b))", "-1.5, 0, math.MaxFloat64, math.SmallestNonzeroFloat64"},
+	{"Bool", "bool", 1, "if v {\nb[0] = 1\n} else {\nb[0] = 0\n}", "b[0] != 0", "true, false, false, true"},
+}
+
+var srcTmpl = template.Must(template.New("src").Parse(`// Code generated by gen_typed.go. DO NOT EDIT.
+
+package array
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/golang/protobuf/proto"
+)
+{{range .}}
+// Array{{.Name}} is an implementation of Array with {{.Type}} element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type Array{{.Name}} struct {
+	Array32Index
+	Data []{{.Type}}
+}
+
+// NewArray{{.Name}} creates a Array{{.Name}}
+func NewArray{{.Name}}(index []uint32, elts []{{.Type}}) (a *Array{{.Name}}, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &Array{{.Name}}{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by ` + "`idx`" + ` and a bool indicate if the value is
+// found.
+func (a *Array{{.Name}}) Get2(idx uint32) ({{.Type}}, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero {{.Type}}
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *Array{{.Name}}) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *Array{{.Name}}) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * {{.Size}}; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *Array{{.Name}}) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *Array{{.Name}}) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]{{.Type}}, len(a.Elts)/{{.Size}})
+	for i := range a.Data {
+		b := a.Elts[i*{{.Size}}:]
+		a.Data[i] = {{.Load}}
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *Array{{.Name}}) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*{{.Size}})
+	for i, v := range a.Data {
+		b := elts[i*{{.Size}}:]
+		{{.Put}}
+	}
+	return elts
+}
+{{end}}`))
+
+var testTmpl = template.Must(template.New("test").Parse(`// Code generated by gen_typed.go. DO NOT EDIT.
+
+package array_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/array"
+	"github.com/openacid/slim/serialize"
+)
+{{range .}}
+func TestArray{{.Name}}(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []{{.Type}}{ {{.Samples}} }
+
+	if _, err := array.NewArray{{.Name}}(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArray{{.Name}}(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.Array{{.Name}}{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+{{end}}`))
+
+func generate(tmpl *template.Template, fn string) {
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, types); err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(fn, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	generate(srcTmpl, "typed.go")
+	generate(testTmpl, "typed_test.go")
+}
@@ -2,6 +2,8 @@ package array
 
 import (
 	"errors"
+	mbits "math/bits"
+	"sort"
 
 	"github.com/openacid/slim/bits"
 	"github.com/openacid/slim/prototype"
@@ -16,14 +18,85 @@ import (
 //
 // Most time is spent on Bitmaps and Offsets access:
 // L1 or L2 cache assess costs 0.5 ns and 7 ns.
+//
+// Set or Delete does not update Offsets of all the bitmap words after the
+// updated one.
+// Instead it records a pending delta of these Offsets in a short sorted log,
+// which a lookup applies with a binary search.
+// When there are maxPendingDeltas of them, they are applied to Offsets in one
+// pass.
+// A lookup never writes, thus lookups are safe for concurrent use, as long as
+// there is no concurrent update.
 type Array32Index struct {
 	prototype.Array32Storage
+
+	// pending are deltas not yet applied to Offsets, sorted by word.
+	pending []offsetDelta
+
+	// wordOffsets is the number of indexes before every word, including empty
+	// ones, without pending deltas, the same as Offsets.
+	// Offsets of an empty word is 0 and is set from it in O(1) when an index
+	// is added to the word.
+	// It is built when an index is added to an empty word for the first time.
+	wordOffsets []uint32
+}
+
+// offsetDelta is a pending change of Offsets of words >= `word`.
+// `sum` is the total change of these words, including changes of all the
+// previous entries.
+type offsetDelta struct {
+	word uint32
+	sum  int32
 }
 
+// maxPendingDeltas is the max number of pending deltas before they are applied
+// to Offsets.
+const maxPendingDeltas = 64
+
 func (a *Array32Index) GetStorage() *prototype.Array32Storage {
+	a.applyPending()
+	// the storage may be modified or reloaded by the caller.
+	a.wordOffsets = nil
 	return &a.Array32Storage
 }
 
+// Reset clears the index, including pending deltas.
+func (a *Array32Index) Reset() {
+	a.Array32Storage.Reset()
+	a.pending = nil
+	a.wordOffsets = nil
+}
+
+// XXX_Size calculates the size with pending deltas applied, without updating
+// the index.
+func (a *Array32Index) XXX_Size() int {
+	return a.freshStorage().XXX_Size()
+}
+
+// XXX_Marshal marshals the index with pending deltas applied, without updating
+// the index.
+func (a *Array32Index) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return a.freshStorage().XXX_Marshal(b, deterministic)
+}
+
+// RebuildOffsets applies all pending deltas of Offsets after Set or Delete.
+// It must not be called concurrently with other methods.
+func (a *Array32Index) RebuildOffsets() {
+	a.applyPending()
+}
+
+// freshStorage returns the storage if there is no pending delta, otherwise a
+// copy of it with pending deltas applied.
+func (a *Array32Index) freshStorage() *prototype.Array32Storage {
+	if len(a.pending) == 0 {
+		return &a.Array32Storage
+	}
+
+	sto := a.Array32Storage
+	sto.Offsets = a.appliedOffsets(make([]uint32, len(a.Offsets)))
+	return &sto
+}
+
 // ErrIndexNotAscending means indexes to initialize a Array must be in
 // ascending order.
 var ErrIndexNotAscending = errors.New("index must be an ascending ordered slice")
@@ -55,6 +128,8 @@ func (a *Array32Index) InitIndexBitmap(index []uint32) error {
 
 	a.Bitmaps = make([]uint64, bmCnt)
 	a.Offsets = make([]uint32, bmCnt)
+	a.pending = nil
+	a.wordOffsets = nil
 
 	nxt := uint32(0)
 	for i := 0; i < len(index); i++ {
@@ -83,7 +158,7 @@ func (a *Array32Index) GetEltIndex(idx uint32) (uint32, bool) {
 		return 0, false
 	}
 
-	base := a.Offsets[iBm]
+	base := a.offset(iBm)
 	cnt1 := bits.OnesCount64Before(bmWord, uint(iBit))
 	return base + uint32(cnt1), true
 }
@@ -109,3 +184,282 @@ func (a *Array32Index) appendIndex(index uint32) {
 
 	a.Cnt++
 }
+
+// setIndex adds `idx` into index bitmap.
+// It returns the position of the elt of `idx` in a.Elts and a bool indicating
+// if `idx` is added, false if it is already present.
+func (a *Array32Index) setIndex(idx uint32) (uint32, bool) {
+
+	iBm, iBit := bmBit(idx)
+
+	if l := uint32(len(a.Bitmaps)); iBm >= l {
+		a.Bitmaps = append(a.Bitmaps, make([]uint64, iBm+1-l)...)
+		a.Offsets = append(a.Offsets, make([]uint32, iBm+1-l)...)
+		if a.wordOffsets != nil {
+			// no pending delta is on the new words.
+			o := a.Cnt - a.pendingOf(iBm)
+			for i := l; i <= iBm; i++ {
+				a.wordOffsets = append(a.wordOffsets, o)
+			}
+		}
+	}
+
+	var bmWord = &a.Bitmaps[iBm]
+	if *bmWord == 0 {
+		a.initWordOffsets()
+		a.Offsets[iBm] = a.wordOffsets[iBm]
+	}
+
+	pos := a.offset(iBm) + uint32(bits.OnesCount64Before(*bmWord, uint(iBit)))
+	if (*bmWord>>iBit)&1 == 1 {
+		return pos, false
+	}
+
+	*bmWord |= uint64(1) << iBit
+	a.Cnt++
+	a.addPending(iBm+1, 1)
+
+	return pos, true
+}
+
+// deleteIndex removes `idx` from index bitmap.
+// It returns the position of the elt of `idx` in a.Elts and a bool indicating
+// if `idx` is removed, false if it is not present.
+func (a *Array32Index) deleteIndex(idx uint32) (uint32, bool) {
+
+	pos, found := a.GetEltIndex(idx)
+	if !found {
+		return 0, false
+	}
+
+	iBm, iBit := bmBit(idx)
+	a.Bitmaps[iBm] &^= uint64(1) << iBit
+	a.Cnt--
+	a.addPending(iBm+1, -1)
+
+	// remove trailing empty words, as InitIndexBitmap does not create them.
+	l := len(a.Bitmaps)
+	for l > 0 && a.Bitmaps[l-1] == 0 {
+		l--
+	}
+	a.Bitmaps = a.Bitmaps[:l]
+	a.Offsets = a.Offsets[:l]
+	if a.wordOffsets != nil {
+		a.wordOffsets = a.wordOffsets[:l]
+	}
+	for len(a.pending) > 0 && a.pending[len(a.pending)-1].word >= uint32(l) {
+		a.pending = a.pending[:len(a.pending)-1]
+	}
+
+	return pos, true
+}
+
+// offset returns the number of indexes before non-empty word `iBm`, with
+// pending deltas applied.
+func (a *Array32Index) offset(iBm uint32) uint32 {
+	return a.Offsets[iBm] + a.pendingOf(iBm)
+}
+
+// pendingOf returns the pending delta of Offsets of word `iBm`.
+func (a *Array32Index) pendingOf(iBm uint32) uint32 {
+	i := sort.Search(len(a.pending), func(i int) bool {
+		return a.pending[i].word > iBm
+	})
+	if i == 0 {
+		return 0
+	}
+	return uint32(a.pending[i-1].sum)
+}
+
+// addPending adds `delta` to Offsets of words >= `iBm`.
+// It applies all pending deltas if there are too many.
+func (a *Array32Index) addPending(iBm uint32, delta int32) {
+	if iBm >= uint32(len(a.Bitmaps)) {
+		return
+	}
+
+	i := sort.Search(len(a.pending), func(i int) bool {
+		return a.pending[i].word >= iBm
+	})
+	if i == len(a.pending) || a.pending[i].word != iBm {
+		prev := int32(0)
+		if i > 0 {
+			prev = a.pending[i-1].sum
+		}
+		a.pending = append(a.pending, offsetDelta{})
+		copy(a.pending[i+1:], a.pending[i:])
+		a.pending[i] = offsetDelta{word: iBm, sum: prev}
+	}
+
+	for j := i; j < len(a.pending); j++ {
+		a.pending[j].sum += delta
+	}
+
+	if len(a.pending) >= maxPendingDeltas {
+		a.applyPending()
+	}
+}
+
+// initWordOffsets builds wordOffsets if it is not yet built.
+func (a *Array32Index) initWordOffsets() {
+	if len(a.wordOffsets) == len(a.Bitmaps) {
+		return
+	}
+
+	a.wordOffsets = make([]uint32, len(a.Bitmaps))
+
+	p := 0
+	sum := int32(0)
+	cnt := uint32(0)
+	for i, w := range a.Bitmaps {
+		for p < len(a.pending) && a.pending[p].word <= uint32(i) {
+			sum = a.pending[p].sum
+			p++
+		}
+		a.wordOffsets[i] = cnt - uint32(sum)
+		cnt += uint32(mbits.OnesCount64(w))
+	}
+}
+
+// appliedOffsets stores Offsets with pending deltas applied into `offsets`
+// and returns it.
+// Offsets of empty words are 0, as InitIndexBitmap builds.
+func (a *Array32Index) appliedOffsets(offsets []uint32) []uint32 {
+	addPendingTo(a.pending, a.Offsets, offsets)
+	for i, w := range a.Bitmaps {
+		if w == 0 {
+			offsets[i] = 0
+		}
+	}
+	return offsets
+}
+
+// applyPending applies all pending deltas to Offsets and wordOffsets.
+func (a *Array32Index) applyPending() {
+	if len(a.pending) == 0 {
+		return
+	}
+	a.appliedOffsets(a.Offsets)
+	addPendingTo(a.pending, a.wordOffsets, a.wordOffsets)
+	a.pending = a.pending[:0]
+}
+
+// addPendingTo stores `src` with `pending` deltas applied into `dst`.
+func addPendingTo(pending []offsetDelta, src, dst []uint32) {
+	p := 0
+	sum := int32(0)
+	for i, o := range src {
+		for p < len(pending) && pending[p].word <= uint32(i) {
+			sum = pending[p].sum
+			p++
+		}
+		dst[i] = o + uint32(sum)
+	}
+}
+
+// NextIndex returns the smallest present index >= `idx`, and a bool indicating
+// if there is such an index.
+func (a *Array32Index) NextIndex(idx uint32) (uint32, bool) {
+
+	iBm, iBit := bmBit(idx)
+
+	for l := uint32(len(a.Bitmaps)); iBm < l; iBm++ {
+		bmWord := a.Bitmaps[iBm] >> iBit << iBit
+		if bmWord != 0 {
+			return iBm*bmWidth + uint32(mbits.TrailingZeros64(bmWord)), true
+		}
+		iBit = 0
+	}
+
+	return 0, false
+}
+
+// PrevIndex returns the greatest present index <= `idx`, and a bool indicating
+// if there is such an index.
+func (a *Array32Index) PrevIndex(idx uint32) (uint32, bool) {
+
+	iBm, iBit := bmBit(idx)
+
+	// mask of bits <= iBit
+	mask := ^uint64(0) >> (bmMask - iBit)
+
+	if l := uint32(len(a.Bitmaps)); iBm >= l {
+		if l == 0 {
+			return 0, false
+		}
+		iBm, mask = l-1, ^uint64(0)
+	}
+
+	for i := int(iBm); i >= 0; i-- {
+		bmWord := a.Bitmaps[i] & mask
+		if bmWord != 0 {
+			return uint32(i)*bmWidth + bmMask - uint32(mbits.LeadingZeros64(bmWord)), true
+		}
+		mask = ^uint64(0)
+	}
+
+	return 0, false
+}
+
+// Union returns a new index of indexes present in `a` or `b`.
+func (a *Array32Index) Union(b *Array32Index) *Array32Index {
+	return combineBitmaps(a.Bitmaps, b.Bitmaps, true, func(x, y uint64) uint64 { return x | y })
+}
+
+// Intersect returns a new index of indexes present in both `a` and `b`.
+func (a *Array32Index) Intersect(b *Array32Index) *Array32Index {
+	return combineBitmaps(a.Bitmaps, b.Bitmaps, false, func(x, y uint64) uint64 { return x & y })
+}
+
+// Difference returns a new index of indexes present in `a` but not in `b`.
+func (a *Array32Index) Difference(b *Array32Index) *Array32Index {
+	return combineBitmaps(a.Bitmaps, b.Bitmaps, true, func(x, y uint64) uint64 { return x &^ y })
+}
+
+// combineBitmaps creates an index with bitmap words `op(x[i], y[i])`.
+// A missing word is 0.
+// If `longer` is false, only the words present in both are combined.
+func combineBitmaps(x, y []uint64, longer bool, op func(x, y uint64) uint64) *Array32Index {
+
+	n := len(x)
+	if (len(y) > n) == longer {
+		n = len(y)
+	}
+
+	bms := make([]uint64, n)
+	for i := range bms {
+		var xw, yw uint64
+		if i < len(x) {
+			xw = x[i]
+		}
+		if i < len(y) {
+			yw = y[i]
+		}
+		bms[i] = op(xw, yw)
+	}
+
+	return newIndexFromBitmaps(bms)
+}
+
+// newIndexFromBitmaps creates an index with bitmap words `bms`.
+// It builds Offsets the same way InitIndexBitmap does.
+func newIndexFromBitmaps(bms []uint64) *Array32Index {
+
+	l := len(bms)
+	for l > 0 && bms[l-1] == 0 {
+		l--
+	}
+
+	a := &Array32Index{}
+	a.Bitmaps = bms[:l]
+	a.Offsets = make([]uint32, l)
+
+	for i, w := range a.Bitmaps {
+		if w != 0 {
+			a.Offsets[i] = a.Cnt
+		}
+		a.Cnt += uint32(mbits.OnesCount64(w))
+	}
+
+	return a
+}
@@ -0,0 +1,107 @@
+package array
+
+import (
+	"sort"
+
+	"github.com/openacid/slim/bits"
+	"github.com/openacid/slim/prototype"
+)
+
+// Array64Index implements sparsely distributed 64-bit index with bitmap.
+//
+// Unlike Array32Index, it does not store a bitmap word for every 64 positions
+// from 0 to the max index.
+// It only stores non-empty bitmap words, along with their word ids in
+// ascending order.
+// Thus the space is irrelevant to the value of indexes, but a lookup takes a
+// binary search on word ids.
+type Array64Index struct {
+	prototype.Array64Storage
+}
+
+// GetStorage returns the underlying protobuf storage.
+func (a *Array64Index) GetStorage() *prototype.Array64Storage {
+	return &a.Array64Storage
+}
+
+// bmBit64 calculates bitamp word id and the bit index in the word.
+func bmBit64(idx uint64) (uint64, uint64) {
+	return idx >> 6, idx & 63
+}
+
+// InitIndexBitmap initializes index bitmap for a compacted array.
+// Index must be a ascending array of type unit64, otherwise, return
+// the ErrIndexNotAscending error
+func (a *Array64Index) InitIndexBitmap(index []uint64) error {
+
+	a.Cnt = 0
+	a.Words = []uint64{}
+	a.Bitmaps = []uint64{}
+	a.Offsets = []uint32{}
+
+	for i := 0; i < len(index); i++ {
+		if i > 0 && index[i] <= index[i-1] {
+			return ErrIndexNotAscending
+		}
+		a.appendIndex(index[i])
+	}
+	return nil
+}
+
+// findWord returns the position of bitmap word `w` in Words, and a bool
+// indicating existence.
+func (a *Array64Index) findWord(w uint64) (int, bool) {
+	i := sort.Search(len(a.Words), func(i int) bool {
+		return a.Words[i] >= w
+	})
+	return i, i < len(a.Words) && a.Words[i] == w
+}
+
+// GetEltIndex returns the data position in a.Elts indexed by `idx` and a bool
+// indicating existence.
+// If `idx` does not present it returns `0, false`.
+func (a *Array64Index) GetEltIndex(idx uint64) (uint32, bool) {
+	w, iBit := bmBit64(idx)
+
+	i, found := a.findWord(w)
+	if !found {
+		return 0, false
+	}
+
+	var bmWord = a.Bitmaps[i]
+
+	if ((bmWord >> iBit) & 1) == 0 {
+		return 0, false
+	}
+
+	base := a.Offsets[i]
+	cnt1 := bits.OnesCount64Before(bmWord, uint(iBit))
+	return base + uint32(cnt1), true
+}
+
+// Has returns true if idx is in array, else return false.
+func (a *Array64Index) Has(idx uint64) bool {
+	w, iBit := bmBit64(idx)
+
+	i, found := a.findWord(w)
+	return found && ((a.Bitmaps[i]>>iBit)&1) != 0
+}
+
+// appendIndex add a index into index bitmap.
+// The `index` must be greater than any existent indexes.
+func (a *Array64Index) appendIndex(index uint64) {
+
+	w, iBit := bmBit64(index)
+
+	l := len(a.Words)
+	if l == 0 || a.Words[l-1] != w {
+		a.Words = append(a.Words, w)
+		a.Bitmaps = append(a.Bitmaps, 0)
+		a.Offsets = append(a.Offsets, a.Cnt)
+		l++
+	}
+
+	a.Bitmaps[l-1] |= uint64(1) << iBit
+
+	a.Cnt++
+}
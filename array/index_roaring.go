@@ -0,0 +1,240 @@
+package array
+
+import (
+	mbits "math/bits"
+	"sort"
+
+	"github.com/openacid/slim/bits"
+	"github.com/openacid/slim/prototype"
+)
+
+const (
+	// chunkBits is the number of low bits of indexes in a chunk.
+	chunkBits = 16
+
+	// containerList stores low 16 bits of indexes in ascending order, 4 in a
+	// word.
+	containerList = uint32(0)
+	// containerBitmap stores a bitmap of 65536 bits, followed by the number of
+	// indexes before every bitmap word, 4 16-bit counts in a word.
+	containerBitmap = uint32(1)
+	// containerRun stores runs of consecutive indexes, a run in a word:
+	// 16-bit start, 16-bit length - 1 and 32-bit number of indexes before it.
+	containerRun = uint32(2)
+
+	bitmapWords     = (1 << chunkBits) / 64
+	bitmapRankWords = bitmapWords / 4
+)
+
+// RoaringIndex implements sparsely distributed index like Array32Index does.
+//
+// Array32Index allocates a bitmap word for every 64 positions from 0 to the
+// max index, which is a waste if indexes are few and scattered.
+//
+// RoaringIndex splits indexes into chunks by the high 16 bits, and stores the
+// low 16 bits of every non-empty chunk in one of 3 containers, whichever takes
+// the least space:
+//
+//	list:   sorted low 16 bits, for a sparse chunk.
+//	bitmap: a 65536-bit bitmap with ranks, for a dense chunk.
+//	run:    runs of consecutive indexes.
+//
+// Performance note: Has() and GetEltIndex() find the chunk with a binary
+// search on chunk keys, then search in the container:
+// a binary search in a list or runs, or a bitmap word lookup.
+type RoaringIndex struct {
+	prototype.RoaringStorage
+}
+
+// GetStorage returns the underlying protobuf storage.
+func (a *RoaringIndex) GetStorage() *prototype.RoaringStorage {
+	return &a.RoaringStorage
+}
+
+// InitIndex initializes the index with a ascending array of type unit32,
+// otherwise, return the ErrIndexNotAscending error
+func (a *RoaringIndex) InitIndex(index []uint32) error {
+
+	a.Cnt = 0
+	a.Keys = []uint32{}
+	a.Kinds = []uint32{}
+	a.Starts = []uint32{}
+	a.Offsets = []uint32{}
+	a.Words = []uint64{}
+
+	for s := 0; s < len(index); {
+		if s > 0 && index[s] <= index[s-1] {
+			return ErrIndexNotAscending
+		}
+
+		key := index[s] >> chunkBits
+		lows := []uint16{uint16(index[s])}
+
+		e := s + 1
+		for ; e < len(index) && index[e]>>chunkBits == key; e++ {
+			if index[e] <= index[e-1] {
+				return ErrIndexNotAscending
+			}
+			lows = append(lows, uint16(index[e]))
+		}
+
+		a.appendChunk(key, lows)
+		s = e
+	}
+
+	a.Starts = append(a.Starts, uint32(len(a.Words)))
+	return nil
+}
+
+// appendChunk encodes a chunk with the container that takes the least words.
+func (a *RoaringIndex) appendChunk(key uint32, lows []uint16) {
+
+	runs := toRuns(lows)
+
+	kind := containerList
+	size := (len(lows) + 3) / 4
+	if len(runs) < size {
+		kind, size = containerRun, len(runs)
+	}
+	if bitmapWords+bitmapRankWords < size {
+		kind = containerBitmap
+	}
+
+	a.Keys = append(a.Keys, key)
+	a.Kinds = append(a.Kinds, kind)
+	a.Starts = append(a.Starts, uint32(len(a.Words)))
+	a.Offsets = append(a.Offsets, a.Cnt)
+	a.Cnt += uint32(len(lows))
+
+	switch kind {
+	case containerList:
+		for i := 0; i < len(lows); i += 4 {
+			w := uint64(0)
+			for j := i; j < i+4 && j < len(lows); j++ {
+				w |= uint64(lows[j]) << (uint(j-i) * 16)
+			}
+			a.Words = append(a.Words, w)
+		}
+
+	case containerRun:
+		rank := uint64(0)
+		for _, r := range runs {
+			a.Words = append(a.Words, uint64(r[0])<<48|uint64(r[1]-r[0])<<32|rank)
+			rank += uint64(r[1]-r[0]) + 1
+		}
+
+	case containerBitmap:
+		bm := make([]uint64, bitmapWords)
+		for _, l := range lows {
+			bm[l>>6] |= uint64(1) << (l & 63)
+		}
+		a.Words = append(a.Words, bm...)
+
+		ranks := make([]uint64, bitmapRankWords)
+		rank := uint64(0)
+		for i, w := range bm {
+			ranks[i/4] |= rank << (uint(i%4) * 16)
+			rank += uint64(mbits.OnesCount64(w))
+		}
+		a.Words = append(a.Words, ranks...)
+	}
+}
+
+// toRuns returns the first and last value of every run of consecutive values.
+func toRuns(lows []uint16) [][2]uint16 {
+	runs := [][2]uint16{}
+	for i, l := range lows {
+		if i > 0 && lows[i-1]+1 == l {
+			runs[len(runs)-1][1] = l
+			continue
+		}
+		runs = append(runs, [2]uint16{l, l})
+	}
+	return runs
+}
+
+// GetEltIndex returns the data position in a.Elts indexed by `idx` and a bool
+// indicating existence.
+// If `idx` does not present it returns `0, false`.
+func (a *RoaringIndex) GetEltIndex(idx uint32) (uint32, bool) {
+
+	key := idx >> chunkBits
+	low := uint16(idx)
+
+	keys := a.Keys
+	i := sort.Search(len(keys), func(i int) bool { return keys[i] >= key })
+	if i == len(keys) || keys[i] != key {
+		return 0, false
+	}
+
+	start, end := a.Starts[i], a.Starts[i+1]
+
+	var rank uint32
+	var found bool
+
+	switch a.Kinds[i] {
+	case containerList:
+		n := a.Cnt
+		if i+1 < len(a.Offsets) {
+			n = a.Offsets[i+1]
+		}
+		rank, found = a.listRank(start, n-a.Offsets[i], low)
+	case containerRun:
+		rank, found = a.runRank(start, end, low)
+	case containerBitmap:
+		rank, found = a.bitmapRank(start, low)
+	}
+
+	if !found {
+		return 0, false
+	}
+	return a.Offsets[i] + rank, true
+}
+
+// Has returns true if idx is in array, else return false.
+func (a *RoaringIndex) Has(idx uint32) bool {
+	_, found := a.GetEltIndex(idx)
+	return found
+}
+
+func (a *RoaringIndex) listRank(start, n uint32, low uint16) (uint32, bool) {
+	ws := a.Words[start:]
+	valueAt := func(j int) uint16 {
+		return uint16(ws[j>>2] >> (uint(j&3) * 16))
+	}
+
+	j := sort.Search(int(n), func(j int) bool { return valueAt(j) >= low })
+	if j == int(n) || valueAt(j) != low {
+		return 0, false
+	}
+	return uint32(j), true
+}
+
+func (a *RoaringIndex) runRank(start, end uint32, low uint16) (uint32, bool) {
+	ws := a.Words[start:end]
+
+	// the last run starting at or before `low`
+	j := sort.Search(len(ws), func(j int) bool { return uint16(ws[j]>>48) > low }) - 1
+	if j < 0 {
+		return 0, false
+	}
+
+	w := ws[j]
+	first, last := uint16(w>>48), uint16(w>>48)+uint16(w>>32)
+	if low > last {
+		return 0, false
+	}
+	return uint32(w) + uint32(low-first), true
+}
+
+func (a *RoaringIndex) bitmapRank(start uint32, low uint16) (uint32, bool) {
+	iWord, iBit := uint32(low>>6), uint(low&63)
+
+	bmWord := a.Words[start+iWord]
+	if (bmWord>>iBit)&1 == 0 {
+		return 0, false
+	}
+
+	base := uint16(a.Words[start+bitmapWords+iWord/4] >> ((iWord % 4) * 16))
+	return uint32(base) + uint32(bits.OnesCount64Before(bmWord, iBit)), true
+}
@@ -0,0 +1,210 @@
+package array
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/openacid/slim/prototype"
+)
+
+// ErrPackedWidth is returned if the bit width of a PackedArray is not in
+// [1, 64].
+var ErrPackedWidth = errors.New("bit width must be in [1, 64]")
+
+// ErrPackedOverflow is returned if a value does not fit in the bit width of a
+// PackedArray.
+var ErrPackedOverflow = errors.New("value overflows bit width")
+
+// ErrIndexOutOfRange is returned if an index is not less than the number of
+// elts.
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// PackedArray stores n-bit unsigned integers, for n from 1 to 64, one right
+// after another in a slice of uint64.
+//
+// The i-th elt is at bit `i*n` in Words, crossing at most one word boundary.
+// Thus Get and Set take O(1) time.
+type PackedArray struct {
+	prototype.PackedArrayStorage
+}
+
+// NewPacked creates a PackedArray of `width`-bit elts.
+func NewPacked(width uint32, elts []uint64) (*PackedArray, error) {
+
+	if width < 1 || width > 64 {
+		return nil, ErrPackedWidth
+	}
+
+	p := &PackedArray{}
+	p.Width = width
+	p.Words = make([]uint64, 0, (uint64(len(elts))*uint64(width)+63)/64)
+
+	for _, v := range elts {
+		if err := p.Append(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Len returns the number of elts.
+func (p *PackedArray) Len() uint32 {
+	return p.Cnt
+}
+
+// Get returns the `i`-th elt.
+func (p *PackedArray) Get(i uint32) uint64 {
+	return getPacked(p.Width, i, func(j uint64) uint64 { return p.Words[j] })
+}
+
+// Set sets the `i`-th elt to `v`.
+// It returns ErrIndexOutOfRange if `i` is not less than Len, or
+// ErrPackedOverflow if `v` does not fit in the bit width.
+func (p *PackedArray) Set(i uint32, v uint64) error {
+
+	if i >= p.Cnt {
+		return ErrIndexOutOfRange
+	}
+
+	if v&^widthMask(p.Width) != 0 {
+		return ErrPackedOverflow
+	}
+
+	width := uint64(p.Width)
+	bitPos := uint64(i) * width
+	iWord, iBit := bitPos>>6, bitPos&63
+
+	mask := widthMask(p.Width)
+	p.Words[iWord] = p.Words[iWord]&^(mask<<iBit) | v<<iBit
+	if iBit+width > 64 {
+		p.Words[iWord+1] = p.Words[iWord+1]&^(mask>>(64-iBit)) | v>>(64-iBit)
+	}
+
+	return nil
+}
+
+// Append adds `v` to the end.
+// It returns ErrPackedOverflow if `v` does not fit in the bit width.
+func (p *PackedArray) Append(v uint64) error {
+
+	if v&^widthMask(p.Width) != 0 {
+		return ErrPackedOverflow
+	}
+
+	nWords := (uint64(p.Cnt+1)*uint64(p.Width) + 63) / 64
+	for uint64(len(p.Words)) < nWords {
+		p.Words = append(p.Words, 0)
+	}
+
+	p.Cnt++
+	return p.Set(p.Cnt-1, v)
+}
+
+// widthMask returns a mask of the lowest `width` bits.
+func widthMask(width uint32) uint64 {
+	return ^uint64(0) >> (64 - width)
+}
+
+// getPacked reads the `i`-th `width`-bit elt, with a function returning the
+// `j`-th word.
+func getPacked(width, i uint32, word func(j uint64) uint64) uint64 {
+
+	bitPos := uint64(i) * uint64(width)
+	iWord, iBit := bitPos>>6, bitPos&63
+
+	v := word(iWord) >> iBit
+	if iBit+uint64(width) > 64 {
+		v |= word(iWord+1) << (64 - iBit)
+	}
+
+	return v & widthMask(width)
+}
+
+// PackedConv converts uint64 of `Width` bits.
+//
+// Used with Array32, it implements EltsConverter and Array32 bit-packs all
+// elts in Elts, in the little-endian layout of PackedArray.Words.
+// E.g., SlimTrie stores 5-bit values in 5 bits with:
+//
+//	trie.NewSlimTrie(array.PackedConv{Width: 5}, keys, values)
+//
+// Used as a plain Converter, it converts one elt to the fewest bytes that hold
+// `Width` bits.
+type PackedConv struct {
+	Width uint32
+}
+
+// Marshal converts uint64 to slice of `(Width+7)/8` bytes.
+// It panics with ErrPackedWidth or ErrPackedOverflow on invalid width or elt,
+// as MarshalElts does.
+func (c PackedConv) Marshal(d interface{}) []byte {
+	if c.Width < 1 || c.Width > 64 {
+		panic(ErrPackedWidth)
+	}
+
+	v := d.(uint64)
+	if v&^widthMask(c.Width) != 0 {
+		panic(ErrPackedOverflow)
+	}
+
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, v)
+	return b[:c.GetMarshaledSize(nil)]
+}
+
+// Unmarshal converts slice of `(Width+7)/8` bytes to uint64.
+// It returns number bytes consumed and an uint64.
+func (c PackedConv) Unmarshal(b []byte) (int, interface{}) {
+	size := c.GetMarshaledSize(nil)
+
+	buf := make([]byte, 8)
+	copy(buf, b[:size])
+	return size, binary.LittleEndian.Uint64(buf) & widthMask(c.Width)
+}
+
+// GetMarshaledSize returns `(Width+7)/8`.
+func (c PackedConv) GetMarshaledSize(b []byte) int {
+	return int(c.Width+7) / 8
+}
+
+// MarshalElts bit-packs uint64 elts.
+// It panics with ErrPackedWidth or ErrPackedOverflow on invalid width or elt.
+func (c PackedConv) MarshalElts(elts []interface{}) []byte {
+
+	p := &PackedArray{}
+	p.Width = c.Width
+	if c.Width < 1 || c.Width > 64 {
+		panic(ErrPackedWidth)
+	}
+
+	for _, e := range elts {
+		if err := p.Append(e.(uint64)); err != nil {
+			panic(err)
+		}
+	}
+
+	return wordsToBytes(p.Words)
+}
+
+// UnmarshalElt returns the `i`-th uint64 elt in `b` built by MarshalElts.
+func (c PackedConv) UnmarshalElt(b []byte, i uint32) interface{} {
+	return getPackedBytes(b, c.Width, i)
+}
+
+// wordsToBytes converts words to bytes in little-endian.
+func wordsToBytes(words []uint64) []byte {
+	b := make([]byte, 8*len(words))
+	for i, w := range words {
+		binary.LittleEndian.PutUint64(b[i*8:], w)
+	}
+	return b
+}
+
+// getPackedBytes reads the `i`-th `width`-bit elt from words converted by
+// wordsToBytes.
+func getPackedBytes(b []byte, width, i uint32) uint64 {
+	return getPacked(width, i, func(j uint64) uint64 {
+		return binary.LittleEndian.Uint64(b[j*8:])
+	})
+}
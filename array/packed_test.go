@@ -0,0 +1,213 @@
+package array
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/openacid/slim/serialize"
+)
+
+func TestPackedArray(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	for width := uint32(1); width <= 64; width++ {
+
+		mask := widthMask(width)
+		elts := make([]uint64, 1000)
+		for i := range elts {
+			elts[i] = rnd.Uint64() & mask
+		}
+		elts[0], elts[1] = 0, mask
+
+		p, err := NewPacked(width, elts)
+		if err != nil {
+			t.Fatalf("width %d expect no error but: %s", width, err)
+		}
+
+		if p.Len() != uint32(len(elts)) {
+			t.Fatalf("width %d expect len: %d but: %d", width, len(elts), p.Len())
+		}
+		if want := (len(elts)*int(width) + 63) / 64; len(p.Words) != want {
+			t.Fatalf("width %d expect %d words but: %d", width, want, len(p.Words))
+		}
+
+		for i, want := range elts {
+			if v := p.Get(uint32(i)); v != want {
+				t.Fatalf("width %d Get(%d) expect: %d but: %d", width, i, want, v)
+			}
+		}
+
+		// overwriting an elt does not affect its neighbors
+		for i := range elts {
+			elts[i] = ^elts[i] & mask
+			if err := p.Set(uint32(i), elts[i]); err != nil {
+				t.Fatalf("width %d expect no error but: %s", width, err)
+			}
+		}
+		for i, want := range elts {
+			if v := p.Get(uint32(i)); v != want {
+				t.Fatalf("width %d after Set Get(%d) expect: %d but: %d", width, i, want, v)
+			}
+		}
+
+		if width < 64 {
+			if err := p.Set(0, mask+1); err != ErrPackedOverflow {
+				t.Fatalf("width %d expect ErrPackedOverflow but: %v", width, err)
+			}
+		}
+
+		// out of range, even within spare bits of the last word
+		for _, i := range []uint32{p.Len(), p.Len() + 1, 1 << 20} {
+			if err := p.Set(i, 0); err != ErrIndexOutOfRange {
+				t.Fatalf("width %d Set(%d) expect ErrIndexOutOfRange but: %v", width, i, err)
+			}
+		}
+	}
+}
+
+func TestPackedArraySerialize(t *testing.T) {
+
+	elts := []uint64{5, 0, 31, 17, 3, 30, 1}
+	p, err := NewPacked(5, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, p); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &PackedArray{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	if loaded.Width != 5 || loaded.Len() != uint32(len(elts)) {
+		t.Fatalf("expect width 5 len %d but: %d %d", len(elts), loaded.Width, loaded.Len())
+	}
+	for i, want := range elts {
+		if v := loaded.Get(uint32(i)); v != want {
+			t.Fatalf("Get(%d) expect: %d but: %d", i, want, v)
+		}
+	}
+}
+
+func TestPackedConv(t *testing.T) {
+
+	c := PackedConv{Width: 20}
+
+	b := c.Marshal(uint64(0xabcde))
+	if len(b) != 3 || c.GetMarshaledSize(b) != 3 {
+		t.Fatalf("expect 3 bytes but: %v", b)
+	}
+
+	n, v := c.Unmarshal(b)
+	if n != 3 || v.(uint64) != 0xabcde {
+		t.Fatalf("expect 3, 0xabcde but: %d, %x", n, v)
+	}
+
+	for _, c := range []struct {
+		conv PackedConv
+		v    uint64
+		want error
+	}{
+		{PackedConv{Width: 5}, 100, ErrPackedOverflow},
+		{PackedConv{Width: 20}, 1 << 20, ErrPackedOverflow},
+		{PackedConv{Width: 0}, 0, ErrPackedWidth},
+		{PackedConv{Width: 65}, 0, ErrPackedWidth},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != c.want {
+					t.Fatalf("width: %d; input: %d; expect panic %v but: %v",
+						c.conv.Width, c.v, c.want, r)
+				}
+			}()
+			c.conv.Marshal(c.v)
+		}()
+	}
+
+	if b := (PackedConv{Width: 64}).Marshal(uint64(1<<64 - 1)); len(b) != 8 {
+		t.Fatalf("expect 8 bytes but: %v", b)
+	}
+}
+
+func TestArray32Packed(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 64, 300, 1000}
+	elts := []uint64{3, 0, 31, 7, 16, 30}
+
+	ca, err := New(PackedConv{Width: 5}, index, elts)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	// 6 elts of 5 bits in one word
+	if len(ca.Elts) != 8 {
+		t.Fatalf("expect 8 bytes of elts but: %d", len(ca.Elts))
+	}
+
+	check := func(ca *Array32, index []uint32, elts []uint64) {
+		for i, idx := range index {
+			v, found := ca.Get2(idx)
+			if !found || v.(uint64) != elts[i] {
+				t.Fatalf("Get2 i:%d expect: %d, act: %v %v", idx, elts[i], v, found)
+			}
+		}
+		if v, found := ca.Get2(3); found {
+			t.Fatalf("Get2 i:3 expect not found but: %v", v)
+		}
+
+		i := 0
+		ca.Range(func(idx uint32, v interface{}) bool {
+			if idx != index[i] || v.(uint64) != elts[i] {
+				t.Fatalf("Range expect: %d %d, act: %d %v", index[i], elts[i], idx, v)
+			}
+			i++
+			return true
+		})
+		if i != len(index) {
+			t.Fatalf("Range expect %d elts but: %d", len(index), i)
+		}
+	}
+
+	check(ca, index, elts)
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, ca); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	loaded := &Array32{Converter: PackedConv{Width: 5}}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	check(loaded, index, elts)
+
+	loaded.Set(2, uint64(9))
+	loaded.Set(64, uint64(8))
+	if !loaded.Delete(300) || loaded.Delete(301) {
+		t.Fatalf("expect to delete 300 only")
+	}
+	check(loaded, []uint32{1, 2, 5, 9, 64, 1000}, []uint64{3, 9, 0, 31, 8, 30})
+
+	sub := &Array32Index{}
+	if err := sub.InitIndexBitmap([]uint32{2, 9, 1000, 2000}); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	pr := loaded.Project(sub)
+	i := 0
+	want := []uint64{9, 31, 30}
+	pr.Range(func(idx uint32, v interface{}) bool {
+		if v.(uint64) != want[i] {
+			t.Fatalf("Project expect: %d, act: %v", want[i], v)
+		}
+		i++
+		return true
+	})
+	if i != len(want) {
+		t.Fatalf("Project expect %d elts but: %d", len(want), i)
+	}
+}
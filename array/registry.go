@@ -0,0 +1,128 @@
+package array
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/openacid/slim/marshal"
+)
+
+// ErrUnknownConverter is returned if the converter name in serialized data is
+// not registered and there is no Converter specified.
+var ErrUnknownConverter = errors.New("converter name is not registered")
+
+// ErrConverterMismatch is returned if the converter name in serialized data is
+// not the name of the specified Converter.
+var ErrConverterMismatch = errors.New("converter does not match the one in data")
+
+var (
+	registryMu sync.RWMutex
+
+	// convByName maps a name to the Converter to use when loading data.
+	convByName = map[string]Converter{}
+
+	// namesByType lists registered Converters and their names by type.
+	// It is not a map keyed by Converter, because a value of a comparable type
+	// may still hold an unhashable value in an interface field.
+	namesByType = map[reflect.Type][]namedConv{}
+)
+
+type namedConv struct {
+	conv Converter
+	name string
+}
+
+func init() {
+	RegisterConverter("u8", marshal.U8{})
+	RegisterConverter("u16", U16Conv{})
+	RegisterConverter("u16", marshal.U16{})
+	RegisterConverter("u32", U32Conv{})
+	RegisterConverter("u32", marshal.U32{})
+	RegisterConverter("u64", marshal.U64{})
+	RegisterConverter("i8", marshal.I8{})
+	RegisterConverter("i16", marshal.I16{})
+	RegisterConverter("i32", marshal.I32{})
+	RegisterConverter("i64", marshal.I64{})
+	RegisterConverter("f32", marshal.F32{})
+	RegisterConverter("f64", marshal.F64{})
+	RegisterConverter("bool", marshal.Bool{})
+	RegisterConverter("u32to3byte", U32to3ByteConv{})
+	RegisterConverter("string16", marshal.String16{})
+	RegisterConverter("bytes16", marshal.Bytes16{})
+}
+
+// RegisterConverter registers a Converter, or a marshal.Marshaller, with a
+// stable name.
+// The name is written into serialized Array32, to choose the Converter when
+// loading it, or to detect a mismatched one.
+//
+// Several compatible Converters can be registered with the same name.
+// The first one is used when loading data without a Converter specified.
+//
+// `conv` must be comparable, e.g., a struct without slice or map, otherwise it
+// panics.
+func RegisterConverter(name string, conv Converter) {
+
+	if !reflect.TypeOf(conv).Comparable() {
+		panic("converter is not comparable")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := convByName[name]; !ok {
+		convByName[name] = conv
+	}
+
+	typ := reflect.TypeOf(conv)
+	for i, nc := range namesByType[typ] {
+		if convEqual(nc.conv, conv) {
+			namesByType[typ][i].name = name
+			return
+		}
+	}
+	namesByType[typ] = append(namesByType[typ], namedConv{conv: conv, name: name})
+}
+
+// ConverterByName returns the first Converter registered with `name` and a
+// bool indicating if there is one.
+func ConverterByName(name string) (Converter, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	conv, ok := convByName[name]
+	return conv, ok
+}
+
+// ConverterName returns the registered name of `conv` and a bool indicating if
+// it is registered.
+//
+// A Converter that can not be compared, e.g., one holding a slice in an
+// interface field, is never registered.
+func ConverterName(conv Converter) (string, bool) {
+	if conv == nil {
+		return "", false
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	for _, nc := range namesByType[reflect.TypeOf(conv)] {
+		if convEqual(nc.conv, conv) {
+			return nc.name, true
+		}
+	}
+	return "", false
+}
+
+// convEqual compares two Converters of the same type.
+// It returns false instead of panicking if they can not be compared.
+func convEqual(a, b Converter) (eq bool) {
+	defer func() {
+		if recover() != nil {
+			eq = false
+		}
+	}()
+	return a == b
+}
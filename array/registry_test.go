@@ -0,0 +1,189 @@
+package array
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	proto "github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/marshal"
+	"github.com/openacid/slim/serialize"
+)
+
+type testRegConv struct{ U32Conv }
+
+// convWithSliceField is not comparable.
+type convWithSliceField struct {
+	U32Conv
+	names []string
+}
+
+func TestConverterRegistry(t *testing.T) {
+
+	cases := []struct {
+		conv Converter
+		name string
+	}{
+		{U16Conv{}, "u16"},
+		{marshal.U16{}, "u16"},
+		{U32Conv{}, "u32"},
+		{marshal.U32{}, "u32"},
+		{marshal.I64{}, "i64"},
+		{marshal.String16{}, "string16"},
+	}
+
+	for _, c := range cases {
+		name, ok := ConverterName(c.conv)
+		if !ok || name != c.name {
+			t.Fatalf("%T expect name: %s but: %s %v", c.conv, c.name, name, ok)
+		}
+	}
+
+	// the first registered one is used for loading
+	conv, ok := ConverterByName("u32")
+	if !ok || conv != (U32Conv{}) {
+		t.Fatalf("expect U32Conv but: %v %v", conv, ok)
+	}
+
+	if _, ok := ConverterName(ByteConv{EltSize: 3}); ok {
+		t.Fatalf("expect ByteConv not registered")
+	}
+	if _, ok := ConverterName(nil); ok {
+		t.Fatalf("expect nil not registered")
+	}
+	if _, ok := ConverterByName("foo"); ok {
+		t.Fatalf("expect foo not registered")
+	}
+
+	RegisterConverter("test-reg", testRegConv{})
+	if name, ok := ConverterName(testRegConv{}); !ok || name != "test-reg" {
+		t.Fatalf("expect test-reg but: %s %v", name, ok)
+	}
+
+	// a comparable type holding an unhashable value
+	RegisterConverter("test-dict", DictConv{Converter: U32Conv{}})
+	if name, ok := ConverterName(DictConv{Converter: U32Conv{}}); !ok || name != "test-dict" {
+		t.Fatalf("expect test-dict but: %s %v", name, ok)
+	}
+	if name, ok := ConverterName(DictConv{Converter: convWithSliceField{}}); ok {
+		t.Fatalf("expect unhashable converter not registered but: %s", name)
+	}
+}
+
+func TestMarshalUnhashableConverter(t *testing.T) {
+
+	conv := DictConv{Converter: convWithSliceField{}}
+	ca, err := New(conv, []uint32{1, 5}, []uint32{3, 3})
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, ca); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &Array32{Converter: conv}
+	if err := serialize.Unmarshal(bytes.NewReader(buf.Bytes()), loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if v := loaded.Get(5); v.(uint32) != 3 {
+		t.Fatalf("expect 3 but: %v", v)
+	}
+}
+
+func TestMarshalConcurrently(t *testing.T) {
+
+	ca, err := New(marshal.String16{}, []uint32{1, 5, 9}, []string{"a", "bc", "d"})
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	want, err := proto.Marshal(ca)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b, err := proto.Marshal(ca)
+			if err != nil || !bytes.Equal(b, want) {
+				t.Errorf("expect %v but: %v %v", want, b, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestUnmarshalByConverterName(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []string{"a", "bc", "", "def"}
+
+	ca, err := New(marshal.String16{}, index, elts)
+	if err != nil {
+		t.Fatalf("failed new compacted array, err: %s", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, ca); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	data := buf.Bytes()
+
+	// no Converter specified
+	loaded := &Array32{}
+	if err := serialize.Unmarshal(bytes.NewReader(data), loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if loaded.ConverterName != "string16" {
+		t.Fatalf("expect converter name string16 but: %s", loaded.ConverterName)
+	}
+	for i, idx := range index {
+		if v := loaded.Get(idx); v.(string) != elts[i] {
+			t.Fatalf("Get i:%d expect: %q but: %v", idx, elts[i], v)
+		}
+	}
+
+	// the same Converter
+	loaded = &Array32{Converter: marshal.String16{}}
+	if err := serialize.Unmarshal(bytes.NewReader(data), loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	// a mismatched Converter
+	for _, conv := range []Converter{U32Conv{}, ByteConv{EltSize: 2}} {
+		loaded = &Array32{Converter: conv}
+		err = serialize.Unmarshal(bytes.NewReader(data), loaded)
+		if err != ErrConverterMismatch {
+			t.Fatalf("%T expect ErrConverterMismatch but: %v", conv, err)
+		}
+	}
+
+	// an unknown name
+	ca.ConverterName = "foo"
+	b, err := ca.Array32Storage.XXX_Marshal(nil, false)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if err := proto.Unmarshal(b, &Array32{}); err != ErrUnknownConverter {
+		t.Fatalf("expect ErrUnknownConverter but: %v", err)
+	}
+
+	// data without converter name is loaded with the specified Converter
+	ca, _ = New(ByteConv{EltSize: 2}, []uint32{3}, [][]byte{{1, 2}})
+	b, err = proto.Marshal(ca)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	loaded = &Array32{Converter: U16Conv{}}
+	if err := proto.Unmarshal(b, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if v := loaded.Get(3); v.(uint16) != 0x0201 {
+		t.Fatalf("expect 0x0201 but: %v", v)
+	}
+}
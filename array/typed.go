@@ -0,0 +1,993 @@
+// Code generated by gen_typed.go. DO NOT EDIT.
+
+package array
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// ArrayU8 is an implementation of Array with uint8 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayU8 struct {
+	Array32Index
+	Data []uint8
+}
+
+// NewArrayU8 creates a ArrayU8
+func NewArrayU8(index []uint32, elts []uint8) (a *ArrayU8, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayU8{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayU8) Get2(idx uint32) (uint8, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero uint8
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayU8) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayU8) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 1; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayU8) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayU8) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]uint8, len(a.Elts)/1)
+	for i := range a.Data {
+		b := a.Elts[i*1:]
+		a.Data[i] = b[0]
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayU8) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*1)
+	for i, v := range a.Data {
+		b := elts[i*1:]
+		b[0] = v
+	}
+	return elts
+}
+
+// ArrayU16 is an implementation of Array with uint16 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayU16 struct {
+	Array32Index
+	Data []uint16
+}
+
+// NewArrayU16 creates a ArrayU16
+func NewArrayU16(index []uint32, elts []uint16) (a *ArrayU16, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayU16{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayU16) Get2(idx uint32) (uint16, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero uint16
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayU16) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayU16) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 2; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayU16) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayU16) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]uint16, len(a.Elts)/2)
+	for i := range a.Data {
+		b := a.Elts[i*2:]
+		a.Data[i] = binary.LittleEndian.Uint16(b)
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayU16) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*2)
+	for i, v := range a.Data {
+		b := elts[i*2:]
+		binary.LittleEndian.PutUint16(b, v)
+	}
+	return elts
+}
+
+// ArrayU32 is an implementation of Array with uint32 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayU32 struct {
+	Array32Index
+	Data []uint32
+}
+
+// NewArrayU32 creates a ArrayU32
+func NewArrayU32(index []uint32, elts []uint32) (a *ArrayU32, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayU32{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayU32) Get2(idx uint32) (uint32, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero uint32
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayU32) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayU32) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 4; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayU32) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayU32) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]uint32, len(a.Elts)/4)
+	for i := range a.Data {
+		b := a.Elts[i*4:]
+		a.Data[i] = binary.LittleEndian.Uint32(b)
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayU32) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*4)
+	for i, v := range a.Data {
+		b := elts[i*4:]
+		binary.LittleEndian.PutUint32(b, v)
+	}
+	return elts
+}
+
+// ArrayU64 is an implementation of Array with uint64 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayU64 struct {
+	Array32Index
+	Data []uint64
+}
+
+// NewArrayU64 creates a ArrayU64
+func NewArrayU64(index []uint32, elts []uint64) (a *ArrayU64, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayU64{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayU64) Get2(idx uint32) (uint64, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero uint64
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayU64) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayU64) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 8; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayU64) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayU64) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]uint64, len(a.Elts)/8)
+	for i := range a.Data {
+		b := a.Elts[i*8:]
+		a.Data[i] = binary.LittleEndian.Uint64(b)
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayU64) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*8)
+	for i, v := range a.Data {
+		b := elts[i*8:]
+		binary.LittleEndian.PutUint64(b, v)
+	}
+	return elts
+}
+
+// ArrayI8 is an implementation of Array with int8 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayI8 struct {
+	Array32Index
+	Data []int8
+}
+
+// NewArrayI8 creates a ArrayI8
+func NewArrayI8(index []uint32, elts []int8) (a *ArrayI8, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayI8{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayI8) Get2(idx uint32) (int8, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero int8
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayI8) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayI8) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 1; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayI8) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayI8) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]int8, len(a.Elts)/1)
+	for i := range a.Data {
+		b := a.Elts[i*1:]
+		a.Data[i] = int8(b[0])
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayI8) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*1)
+	for i, v := range a.Data {
+		b := elts[i*1:]
+		b[0] = byte(v)
+	}
+	return elts
+}
+
+// ArrayI16 is an implementation of Array with int16 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayI16 struct {
+	Array32Index
+	Data []int16
+}
+
+// NewArrayI16 creates a ArrayI16
+func NewArrayI16(index []uint32, elts []int16) (a *ArrayI16, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayI16{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayI16) Get2(idx uint32) (int16, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero int16
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayI16) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayI16) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 2; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayI16) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayI16) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]int16, len(a.Elts)/2)
+	for i := range a.Data {
+		b := a.Elts[i*2:]
+		a.Data[i] = int16(binary.LittleEndian.Uint16(b))
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayI16) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*2)
+	for i, v := range a.Data {
+		b := elts[i*2:]
+		binary.LittleEndian.PutUint16(b, uint16(v))
+	}
+	return elts
+}
+
+// ArrayI32 is an implementation of Array with int32 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayI32 struct {
+	Array32Index
+	Data []int32
+}
+
+// NewArrayI32 creates a ArrayI32
+func NewArrayI32(index []uint32, elts []int32) (a *ArrayI32, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayI32{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayI32) Get2(idx uint32) (int32, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero int32
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayI32) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayI32) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 4; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayI32) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayI32) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]int32, len(a.Elts)/4)
+	for i := range a.Data {
+		b := a.Elts[i*4:]
+		a.Data[i] = int32(binary.LittleEndian.Uint32(b))
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayI32) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*4)
+	for i, v := range a.Data {
+		b := elts[i*4:]
+		binary.LittleEndian.PutUint32(b, uint32(v))
+	}
+	return elts
+}
+
+// ArrayI64 is an implementation of Array with int64 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayI64 struct {
+	Array32Index
+	Data []int64
+}
+
+// NewArrayI64 creates a ArrayI64
+func NewArrayI64(index []uint32, elts []int64) (a *ArrayI64, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayI64{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayI64) Get2(idx uint32) (int64, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero int64
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayI64) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayI64) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 8; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayI64) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayI64) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]int64, len(a.Elts)/8)
+	for i := range a.Data {
+		b := a.Elts[i*8:]
+		a.Data[i] = int64(binary.LittleEndian.Uint64(b))
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayI64) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*8)
+	for i, v := range a.Data {
+		b := elts[i*8:]
+		binary.LittleEndian.PutUint64(b, uint64(v))
+	}
+	return elts
+}
+
+// ArrayF32 is an implementation of Array with float32 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayF32 struct {
+	Array32Index
+	Data []float32
+}
+
+// NewArrayF32 creates a ArrayF32
+func NewArrayF32(index []uint32, elts []float32) (a *ArrayF32, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayF32{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayF32) Get2(idx uint32) (float32, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero float32
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayF32) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayF32) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 4; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayF32) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayF32) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]float32, len(a.Elts)/4)
+	for i := range a.Data {
+		b := a.Elts[i*4:]
+		a.Data[i] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayF32) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*4)
+	for i, v := range a.Data {
+		b := elts[i*4:]
+		binary.LittleEndian.PutUint32(b, math.Float32bits(v))
+	}
+	return elts
+}
+
+// ArrayF64 is an implementation of Array with float64 element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayF64 struct {
+	Array32Index
+	Data []float64
+}
+
+// NewArrayF64 creates a ArrayF64
+func NewArrayF64(index []uint32, elts []float64) (a *ArrayF64, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayF64{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayF64) Get2(idx uint32) (float64, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero float64
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayF64) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayF64) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 8; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayF64) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayF64) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]float64, len(a.Elts)/8)
+	for i := range a.Data {
+		b := a.Elts[i*8:]
+		a.Data[i] = math.Float64frombits(binary.LittleEndian.Uint64(b))
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayF64) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*8)
+	for i, v := range a.Data {
+		b := elts[i*8:]
+		binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+	}
+	return elts
+}
+
+// ArrayBool is an implementation of Array with bool element.
+//
+// Get2 does not allocate and serialization does not use reflection.
+type ArrayBool struct {
+	Array32Index
+	Data []bool
+}
+
+// NewArrayBool creates a ArrayBool
+func NewArrayBool(index []uint32, elts []bool) (a *ArrayBool, err error) {
+
+	if len(index) != len(elts) {
+		return nil, ErrIndexLen
+	}
+
+	a = &ArrayBool{Data: elts}
+
+	err = a.InitIndexBitmap(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Get2 returns value indexed by `idx` and a bool indicate if the value is
+// found.
+func (a *ArrayBool) Get2(idx uint32) (bool, bool) {
+	i, ok := a.GetEltIndex(idx)
+	if ok {
+		return a.Data[i], true
+	}
+
+	var zero bool
+	return zero, false
+}
+
+// Reset clears the index and Data.
+func (a *ArrayBool) Reset() {
+	a.Array32Index.Reset()
+	a.Data = nil
+}
+
+// XXX_Size returns the size of the protobuf storage with Data in Elts.
+//
+// Data is not converted: Elts is field 4 with a 1 byte tag.
+func (a *ArrayBool) XXX_Size() int {
+	n := a.Array32Index.XXX_Size()
+	if l := len(a.Data) * 1; l > 0 {
+		n += 1 + proto.SizeVarint(uint64(l)) + l
+	}
+	return n
+}
+
+// XXX_Marshal marshals a copy of the protobuf storage with Data in Elts.
+// It does not modify the array thus it is safe to marshal concurrently.
+func (a *ArrayBool) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	st := *a.freshStorage()
+	st.Elts = a.marshalData()
+	return st.XXX_Marshal(b, deterministic)
+}
+
+// XXX_Unmarshal loads the protobuf storage and Data from Elts.
+func (a *ArrayBool) XXX_Unmarshal(b []byte) error {
+	err := a.Array32Storage.XXX_Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	a.Data = make([]bool, len(a.Elts)/1)
+	for i := range a.Data {
+		b := a.Elts[i*1:]
+		a.Data[i] = b[0] != 0
+	}
+	a.Elts = nil
+
+	return nil
+}
+
+// marshalData converts Data to bytes in little-endian.
+func (a *ArrayBool) marshalData() []byte {
+	elts := make([]byte, len(a.Data)*1)
+	for i, v := range a.Data {
+		b := elts[i*1:]
+		if v {
+			b[0] = 1
+		} else {
+			b[0] = 0
+		}
+	}
+	return elts
+}
@@ -0,0 +1,795 @@
+// Code generated by gen_typed.go. DO NOT EDIT.
+
+package array_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openacid/slim/array"
+	"github.com/openacid/slim/serialize"
+)
+
+func TestArrayU8(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []uint8{1, 0, 255, 7}
+
+	if _, err := array.NewArrayU8(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayU8(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayU8{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayU16(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []uint16{12, 15, 19, 120}
+
+	if _, err := array.NewArrayU16(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayU16(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayU16{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayU32(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []uint32{1, 0, math.MaxUint32, 7}
+
+	if _, err := array.NewArrayU32(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayU32(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayU32{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayU64(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []uint64{1, 0, math.MaxUint64, 7}
+
+	if _, err := array.NewArrayU64(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayU64(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayU64{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayI8(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []int8{-1, 0, math.MaxInt8, math.MinInt8}
+
+	if _, err := array.NewArrayI8(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayI8(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayI8{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayI16(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []int16{-1, 0, math.MaxInt16, math.MinInt16}
+
+	if _, err := array.NewArrayI16(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayI16(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayI16{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayI32(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []int32{-1, 0, math.MaxInt32, math.MinInt32}
+
+	if _, err := array.NewArrayI32(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayI32(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayI32{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayI64(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []int64{-1, 0, math.MaxInt64, math.MinInt64}
+
+	if _, err := array.NewArrayI64(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayI64(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayI64{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayF32(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []float32{-1.5, 0, math.MaxFloat32, math.SmallestNonzeroFloat32}
+
+	if _, err := array.NewArrayF32(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayF32(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayF32{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayF64(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []float64{-1.5, 0, math.MaxFloat64, math.SmallestNonzeroFloat64}
+
+	if _, err := array.NewArrayF64(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayF64(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayF64{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
+
+func TestArrayBool(t *testing.T) {
+
+	index := []uint32{1, 5, 9, 203}
+	elts := []bool{true, false, false, true}
+
+	if _, err := array.NewArrayBool(index[:3], elts); err != array.ErrIndexLen {
+		t.Fatalf("expect ErrIndexLen but: %v", err)
+	}
+
+	a, err := array.NewArrayBool(index, elts)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for i, idx := range index {
+		v, found := a.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+	if v, found := a.Get2(2); found {
+		t.Fatalf("Get2 i:2 expect not found but: %v", v)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Get2(9)
+		a.Get2(10)
+	})
+	if allocs != 0 {
+		t.Fatalf("expect Get2 not to allocate but: %v", allocs)
+	}
+
+	// the same as reflection based array.Marshal
+	want, err := array.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	got, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Fatalf("expect marshaled: %v but: %v", want, got)
+	}
+	if n := a.XXX_Size(); n != len(want) {
+		t.Fatalf("expect size: %d but: %d", len(want), n)
+	}
+	if len(a.Elts) != 0 {
+		t.Fatalf("expect marshaling not to modify Elts but: %v", a.Elts)
+	}
+
+	buf := new(bytes.Buffer)
+	if _, err := serialize.Marshal(buf, a); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := &array.ArrayBool{}
+	if err := serialize.Unmarshal(buf, loaded); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(a.Data, loaded.Data) || len(loaded.Elts) != 0 {
+		t.Fatalf("expect Data: %v but: %v", a.Data, loaded.Data)
+	}
+	for i, idx := range index {
+		v, found := loaded.Get2(idx)
+		if !found || v != elts[i] {
+			t.Fatalf("Get2 i:%d expect: %v, act: %v %v", idx, elts[i], v, found)
+		}
+	}
+}
@@ -10,3 +10,10 @@ func TestGetVersion(t *testing.T) {
 		t.Errorf("version should be a32")
 	}
 }
+
+func TestGetVersion64(t *testing.T) {
+	a, _ := New64(U32Conv{}, nil, []uint32{})
+	if a.GetVersion() != "a64" {
+		t.Errorf("version should be a64")
+	}
+}
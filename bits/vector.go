@@ -0,0 +1,200 @@
+package bits
+
+import (
+	gobits "math/bits"
+	"sort"
+)
+
+const (
+	// blockWords is the number of words in a rank block.
+	blockWords = 8
+	// selectSample is the interval of "1"s or "0"s between select hints.
+	selectSample = 512
+	// relBits is the bit width of a relative rank in a block.
+	relBits = 9
+	relMask = uint64(1)<<relBits - 1
+)
+
+// Vector is an append-only bit vector supporting rank and select.
+//
+// Bits are grouped in blocks of 512 bits.
+// For every block it stores two words in `Ranks`:
+// the number of "1" before the block, and the numbers of "1" in the block
+// before every word 1 to 7 of it, 9 bits each.
+// Thus Rank1 takes 3 memory accesses.
+//
+// For every 512-th "1" and "0", it stores the block where it is, as a hint to
+// narrow down the binary search in Select1 and Select0.
+//
+// It has only plain fields and no dependency; array.BitVector wraps it to
+// serialize it as a protobuf message.
+type Vector struct {
+	// N is the number of bits.
+	N uint64
+	// Ones is the number of "1".
+	Ones uint64
+
+	// Words are the bits.
+	Words []uint64
+	// Ranks are 2 words for every 512 bits: absolute and relative ranks.
+	Ranks []uint64
+	// Select1Hints are the blocks of every 512-th "1".
+	Select1Hints []uint32
+	// Select0Hints are the blocks of every 512-th "0".
+	Select0Hints []uint32
+}
+
+// NewVector creates a Vector of bits in `bs`.
+func NewVector(bs []bool) *Vector {
+	v := &Vector{}
+	for _, b := range bs {
+		v.Append(b)
+	}
+	return v
+}
+
+// Len returns the number of bits.
+func (v *Vector) Len() uint64 {
+	return v.N
+}
+
+// Append adds a bit to the end.
+func (v *Vector) Append(bit bool) {
+
+	i := v.N
+	iw, ib := i>>6, i&63
+	blk, w := iw/blockWords, iw%blockWords
+
+	if ib == 0 {
+		v.Words = append(v.Words, 0)
+		if w == 0 {
+			v.Ranks = append(v.Ranks, v.Ones, 0)
+		} else {
+			rel := v.Ones - v.Ranks[2*blk]
+			v.Ranks[2*blk+1] |= rel << (relBits * (w - 1))
+		}
+	}
+
+	if bit {
+		if v.Ones%selectSample == 0 {
+			v.Select1Hints = append(v.Select1Hints, uint32(blk))
+		}
+		v.Words[iw] |= uint64(1) << ib
+		v.Ones++
+	} else {
+		if (i-v.Ones)%selectSample == 0 {
+			v.Select0Hints = append(v.Select0Hints, uint32(blk))
+		}
+	}
+
+	v.N++
+}
+
+// Get returns the bit at position `i`.
+// It returns false if `i` is out of range.
+func (v *Vector) Get(i uint64) bool {
+	if i >= v.N {
+		return false
+	}
+	return v.Words[i>>6]>>(i&63)&1 == 1
+}
+
+// Rank1 returns the number of "1" before position `i`, excluding `i`.
+func (v *Vector) Rank1(i uint64) uint64 {
+	if i >= v.N {
+		return v.Ones
+	}
+
+	iw, ib := i>>6, i&63
+	blk, w := iw/blockWords, iw%blockWords
+
+	return v.rank1Word(blk, w) + uint64(OnesCount64Before(v.Words[iw], uint(ib)))
+}
+
+// Rank0 returns the number of "0" before position `i`, excluding `i`.
+func (v *Vector) Rank0(i uint64) uint64 {
+	if i > v.N {
+		i = v.N
+	}
+	return i - v.Rank1(i)
+}
+
+// rank1Word returns the number of "1" before word `w` in block `blk`.
+func (v *Vector) rank1Word(blk, w uint64) uint64 {
+	r := v.Ranks[2*blk]
+	if w > 0 {
+		r += v.Ranks[2*blk+1] >> (relBits * (w - 1)) & relMask
+	}
+	return r
+}
+
+// Select1 returns the position of the `k`-th "1", counting from 0, and a bool
+// indicating if there is such a "1".
+//
+// For a "1" at position `i`, `Select1(Rank1(i))` returns `i`.
+func (v *Vector) Select1(k uint64) (uint64, bool) {
+	if k >= v.Ones {
+		return 0, false
+	}
+
+	ones := func(blk, w uint64) uint64 { return v.rank1Word(blk, w) }
+	return v.selectBit(k, v.Select1Hints, ones, false), true
+}
+
+// Select0 returns the position of the `k`-th "0", counting from 0, and a bool
+// indicating if there is such a "0".
+func (v *Vector) Select0(k uint64) (uint64, bool) {
+	if k >= v.N-v.Ones {
+		return 0, false
+	}
+
+	zeros := func(blk, w uint64) uint64 {
+		return (blk*blockWords+w)*64 - v.rank1Word(blk, w)
+	}
+	return v.selectBit(k, v.Select0Hints, zeros, true), true
+}
+
+// selectBit finds the `k`-th bit, with a function counting such bits before
+// word `w` in block `blk`.
+// If `flip` is true it looks for "0".
+func (v *Vector) selectBit(k uint64, hints []uint32, before func(blk, w uint64) uint64, flip bool) uint64 {
+
+	nBlocks := uint64(len(v.Ranks) / 2)
+
+	h := k / selectSample
+	lo, hi := uint64(hints[h]), nBlocks
+	if h+1 < uint64(len(hints)) {
+		hi = uint64(hints[h+1]) + 1
+	}
+
+	// the last block with less than or equal to `k` bits before it.
+	blk := lo + uint64(sort.Search(int(hi-lo), func(j int) bool {
+		return before(lo+uint64(j), 0) > k
+	})) - 1
+
+	nWords := uint64(len(v.Words)) - blk*blockWords
+	if nWords > blockWords {
+		nWords = blockWords
+	}
+
+	w := uint64(0)
+	for w+1 < nWords && before(blk, w+1) <= k {
+		w++
+	}
+
+	iw := blk*blockWords + w
+	word := v.Words[iw]
+	if flip {
+		word = ^word
+	}
+
+	return iw*64 + selectInWord(word, k-before(blk, w))
+}
+
+// selectInWord returns the position of the `k`-th "1" in `word`.
+func selectInWord(word uint64, k uint64) uint64 {
+	for ; k > 0; k-- {
+		word &= word - 1
+	}
+	return uint64(gobits.TrailingZeros64(word))
+}
@@ -0,0 +1,127 @@
+package bits_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/openacid/slim/bits"
+)
+
+func makeBits(n int, density float64, seed int64) []bool {
+	rnd := rand.New(rand.NewSource(seed))
+	bs := make([]bool, n)
+	for i := range bs {
+		bs[i] = rnd.Float64() < density
+	}
+	return bs
+}
+
+func checkVector(t *testing.T, v *bits.Vector, bs []bool) {
+
+	if v.Len() != uint64(len(bs)) {
+		t.Fatalf("expect len: %d but: %d", len(bs), v.Len())
+	}
+
+	ones, zeros := []uint64{}, []uint64{}
+	for i, b := range bs {
+		ii := uint64(i)
+
+		if v.Get(ii) != b {
+			t.Fatalf("Get(%d) expect: %v", i, b)
+		}
+		if r := v.Rank1(ii); r != uint64(len(ones)) {
+			t.Fatalf("Rank1(%d) expect: %d but: %d", i, len(ones), r)
+		}
+		if r := v.Rank0(ii); r != uint64(len(zeros)) {
+			t.Fatalf("Rank0(%d) expect: %d but: %d", i, len(zeros), r)
+		}
+
+		if b {
+			ones = append(ones, ii)
+		} else {
+			zeros = append(zeros, ii)
+		}
+	}
+
+	n := uint64(len(bs))
+	if v.Get(n) {
+		t.Fatalf("Get out of range expect false")
+	}
+	if r := v.Rank1(n + 10); r != uint64(len(ones)) {
+		t.Fatalf("Rank1 out of range expect: %d but: %d", len(ones), r)
+	}
+
+	for k, want := range ones {
+		got, ok := v.Select1(uint64(k))
+		if !ok || got != want {
+			t.Fatalf("Select1(%d) expect: %d but: %d %v", k, want, got, ok)
+		}
+	}
+	if _, ok := v.Select1(uint64(len(ones))); ok {
+		t.Fatalf("Select1 beyond ones expect false")
+	}
+
+	for k, want := range zeros {
+		got, ok := v.Select0(uint64(k))
+		if !ok || got != want {
+			t.Fatalf("Select0(%d) expect: %d but: %d %v", k, want, got, ok)
+		}
+	}
+	if _, ok := v.Select0(uint64(len(zeros))); ok {
+		t.Fatalf("Select0 beyond zeros expect false")
+	}
+}
+
+func TestVector(t *testing.T) {
+
+	cases := []struct {
+		name    string
+		n       int
+		density float64
+	}{
+		{"empty", 0, 0.5},
+		{"one", 1, 1},
+		{"all0", 5000, 0},
+		{"all1", 5000, 1},
+		{"half", 10000, 0.5},
+		{"sparse", 20000, 0.01},
+		{"dense", 20000, 0.99},
+		{"unaligned", 1023, 0.3},
+	}
+
+	for i, c := range cases {
+		bs := makeBits(c.n, c.density, int64(i))
+		v := bits.NewVector(bs)
+		t.Run(c.name, func(t *testing.T) {
+			checkVector(t, v, bs)
+		})
+	}
+}
+
+var OutputVector uint64
+
+func BenchmarkVectorRank1(b *testing.B) {
+	v := bits.NewVector(makeBits(1<<20, 0.5, 1))
+
+	b.ResetTimer()
+
+	var s uint64
+	for i := 0; i < b.N; i++ {
+		s += v.Rank1(uint64(i) & (1<<20 - 1))
+	}
+	OutputVector = s
+}
+
+func BenchmarkVectorSelect1(b *testing.B) {
+	v := bits.NewVector(makeBits(1<<20, 0.5, 1))
+	mask := uint64(1)<<18 - 1
+
+	b.ResetTimer()
+
+	var s uint64
+	for i := 0; i < b.N; i++ {
+		r, _ := v.Select1(uint64(i) & mask)
+		s += r
+	}
+	OutputVector = s
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/openacid/slim/index"
+)
+
+// ErrKeysNotSorted indicates records in a data file are not in ascending key
+// order.
+var ErrKeysNotSorted = errors.New("keys in data file are not sorted")
+
+// readChunk is the size of a read when looking for the end of a line.
+const readChunk = 4096
+
+// dataFile is a data file of lines "<key>\t<value>\n", in ascending key order.
+// It implements index.DataReader and index.KeyValueReader.
+type dataFile struct {
+	io.ReaderAt
+	size int64
+}
+
+func openDataFile(path string) (*dataFile, *os.File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return &dataFile{ReaderAt: f, size: st.Size()}, f, nil
+}
+
+// readLine returns key and value of the line at `offset` and the offset of the
+// next line.
+func (d *dataFile) readLine(offset int64) (key, value string, next int64, found bool) {
+
+	if offset < 0 || offset >= d.size {
+		return "", "", 0, false
+	}
+
+	line := []byte{}
+	for p := offset; p < d.size; p += readChunk {
+		buf := make([]byte, readChunk)
+		n, err := d.ReadAt(buf, p)
+		if err != nil && err != io.EOF {
+			return "", "", 0, false
+		}
+		buf = buf[:n]
+
+		if i := bytes.IndexByte(buf, '\n'); i >= 0 {
+			line = append(line, buf[:i]...)
+			break
+		}
+		line = append(line, buf...)
+	}
+
+	next = offset + int64(len(line)) + 1
+
+	kv := strings.SplitN(string(line), "\t", 2)
+	if len(kv) < 2 {
+		return kv[0], "", next, true
+	}
+	return kv[0], kv[1], next, true
+}
+
+func (d *dataFile) Read(offset int64, key string) (string, bool) {
+	k, v, _, found := d.readLine(offset)
+	if !found || k != key {
+		return "", false
+	}
+	return v, true
+}
+
+func (d *dataFile) ReadKeyValue(offset int64) (string, string, bool) {
+	k, v, _, found := d.readLine(offset)
+	return k, v, found
+}
+
+// buildIndex creates a SlimIndex of data file `dataPath` and stores it in
+// `indexPath`.
+func buildIndex(dataPath, indexPath string) error {
+
+	data, f, err := openDataFile(dataPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	items := []index.OffsetIndexItem{}
+
+	r := bufio.NewReader(io.NewSectionReader(data, 0, data.size))
+	offset := int64(0)
+	for {
+		line, err := r.ReadString('\n')
+		if len(line) > 0 {
+			key := strings.SplitN(strings.TrimSuffix(line, "\n"), "\t", 2)[0]
+			if len(items) > 0 && items[len(items)-1].Key >= key {
+				return ErrKeysNotSorted
+			}
+			items = append(items, index.OffsetIndexItem{Key: key, Offset: offset})
+			offset += int64(len(line))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	si, err := index.NewSlimIndex(items, data)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(indexPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := si.Marshal(out); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,117 @@
+// slim-serve serves lookups on serialized SlimIndex files over HTTP.
+//
+// A data file consists of lines "<key>\t<value>\n" in ascending key order.
+// Every index is specified by "<name>:<index-file>:<data-file>":
+//
+//	slim-serve -build users:users.idx:users.tsv
+//	slim-serve -addr :8080 users:users.idx:users.tsv
+//
+// Endpoints, all respond with JSON:
+//
+//	GET /get?index=<name>&key=<key>
+//	GET /scan?index=<name>&from=<key>&to=<key>&limit=<n>
+//	GET /stats
+//
+// `index` can be omitted if there is only one index.
+// /scan returns records whose key is in [from, to), an empty `to` means no
+// upper bound.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout is how long to wait for in-flight requests on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+
+	addr := flag.String("addr", ":8080", "address to listen on")
+	build := flag.Bool("build", false, "build index files from data files and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr,
+			"usage: %s [-addr addr] [-build] name:index-file:data-file ...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	tables := []*table{}
+	for _, spec := range flag.Args() {
+		parts := strings.SplitN(spec, ":", 3)
+		if len(parts) != 3 {
+			log.Fatalf("invalid index spec: %q", spec)
+		}
+		name, indexPath, dataPath := parts[0], parts[1], parts[2]
+
+		if *build {
+			if err := buildIndex(dataPath, indexPath); err != nil {
+				log.Fatalf("failed to build index %s: %s", name, err)
+			}
+			continue
+		}
+
+		t, err := loadTable(name, indexPath, dataPath)
+		if err != nil {
+			closeTables(tables)
+			log.Fatalf("failed to load index %s: %s", name, err)
+		}
+		tables = append(tables, t)
+	}
+
+	if *build {
+		return
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: newServer(tables).Handler()}
+
+	// on SIGINT or SIGTERM, stop accepting requests and wait for in-flight
+	// ones to finish, then close tables.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-sigs
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("failed to shut down gracefully: %s", err)
+		}
+	}()
+
+	log.Printf("serving %d indexes on %s", len(tables), *addr)
+	err := srv.ListenAndServe()
+	if err != http.ErrServerClosed {
+		closeTables(tables)
+		log.Fatal(err)
+	}
+
+	// ListenAndServe returns as soon as Shutdown is called, before in-flight
+	// requests finish.
+	<-shutdown
+	closeTables(tables)
+}
+
+// closeTables closes files of all tables.
+func closeTables(tables []*table) {
+	for _, t := range tables {
+		if err := t.Close(); err != nil {
+			log.Printf("failed to close index %s: %s", t.name, err)
+		}
+	}
+}
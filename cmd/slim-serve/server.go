@@ -0,0 +1,343 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openacid/slim/index"
+)
+
+const (
+	// defaultScanLimit is the max number of records /scan returns if `limit`
+	// is not specified.
+	defaultScanLimit = 100
+	// maxScanLimit is the max value of `limit` of /scan.
+	maxScanLimit = 10000
+
+	// latencyBuckets is the number of latency histogram buckets.
+	// Bucket i counts latencies < 2^i micro seconds, and the last one counts
+	// all the others.
+	latencyBuckets = 32
+)
+
+// table is a loaded SlimIndex and its data file.
+type table struct {
+	name string
+
+	si   *index.SlimIndex
+	data *dataFile
+	file *os.File
+}
+
+// loadTable loads the SlimIndex in `indexPath` of data file `dataPath`.
+func loadTable(name, indexPath, dataPath string) (*table, error) {
+
+	data, f, err := openDataFile(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := os.Open(indexPath)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	defer idx.Close()
+
+	si, err := index.NewSlimIndex(nil, data)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if err := si.Unmarshal(idx); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &table{name: name, si: si, data: data, file: f}, nil
+}
+
+func (t *table) Close() error {
+	return t.file.Close()
+}
+
+func (t *table) get(key string) (string, bool) {
+	return t.si.Get2(key)
+}
+
+// scan returns at most `limit` records whose key is in [from, to).
+// An empty `to` means no upper bound.
+//
+// It seeks to the first record whose key >= `from` with the index, and reads
+// at most `limit` records from it.
+func (t *table) scan(from, to string, limit int) []record {
+
+	rst := []record{}
+
+	c, found := t.si.Seek(from)
+	if !found {
+		return rst
+	}
+
+	for len(rst) < limit {
+		k, v, next, found := t.data.readLine(c)
+		if !found || (to != "" && k >= to) {
+			break
+		}
+		rst = append(rst, record{Key: k, Value: v})
+		c = next
+	}
+
+	return rst
+}
+
+type record struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type getResult struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	Found bool   `json:"found"`
+}
+
+type scanResult struct {
+	Items []record `json:"items"`
+}
+
+type errorResult struct {
+	Error string `json:"error"`
+}
+
+// latency records request latencies in a histogram.
+type latency struct {
+	mu      sync.Mutex
+	count   int64
+	total   time.Duration
+	max     time.Duration
+	buckets [latencyBuckets]int64
+}
+
+// latencyStats is a snapshot of latency, in micro seconds.
+// A percentile is the upper bound of the bucket it falls in.
+type latencyStats struct {
+	Count int64   `json:"count"`
+	Avg   float64 `json:"avg_us"`
+	Max   float64 `json:"max_us"`
+	P50   float64 `json:"p50_us"`
+	P99   float64 `json:"p99_us"`
+}
+
+func (l *latency) record(d time.Duration) {
+	us := d.Nanoseconds() / 1000
+
+	b := 0
+	for b < latencyBuckets-1 && us >= int64(1)<<uint(b) {
+		b++
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.count++
+	l.total += d
+	if d > l.max {
+		l.max = d
+	}
+	l.buckets[b]++
+}
+
+func (l *latency) stats() latencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := latencyStats{Count: l.count}
+	if l.count == 0 {
+		return st
+	}
+
+	toUs := func(d time.Duration) float64 { return float64(d.Nanoseconds()) / 1000 }
+
+	st.Avg = toUs(l.total) / float64(l.count)
+	st.Max = toUs(l.max)
+	st.P50 = l.percentile(0.50)
+	st.P99 = l.percentile(0.99)
+	if st.P50 > st.Max {
+		st.P50 = st.Max
+	}
+	if st.P99 > st.Max {
+		st.P99 = st.Max
+	}
+	return st
+}
+
+// percentile returns the upper bound in micro seconds of the bucket where the
+// `p` percentile falls in.
+func (l *latency) percentile(p float64) float64 {
+	n := int64(p*float64(l.count) + 0.5)
+	if n < 1 {
+		n = 1
+	}
+
+	acc := int64(0)
+	for b, c := range l.buckets {
+		acc += c
+		if acc >= n {
+			return float64(int64(1) << uint(b))
+		}
+	}
+	return float64(int64(1) << uint(latencyBuckets-1))
+}
+
+type statsResult struct {
+	Indexes  []string     `json:"indexes"`
+	Found    int64        `json:"found"`
+	NotFound int64        `json:"not_found"`
+	Get      latencyStats `json:"get"`
+	Scan     latencyStats `json:"scan"`
+}
+
+// server serves lookups on tables over HTTP.
+type server struct {
+	tables map[string]*table
+	names  []string
+
+	getLatency  latency
+	scanLatency latency
+
+	mu       sync.Mutex
+	found    int64
+	notFound int64
+}
+
+func newServer(tables []*table) *server {
+	s := &server{tables: make(map[string]*table)}
+	for _, t := range tables {
+		s.tables[t.name] = t
+		s.names = append(s.names, t.name)
+	}
+	sort.Strings(s.names)
+	return s
+}
+
+// Handler returns the http.Handler serving /get, /scan and /stats.
+func (s *server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/get", readOnly(s.handleGet))
+	mux.HandleFunc("/scan", readOnly(s.handleScan))
+	mux.HandleFunc("/stats", readOnly(s.handleStats))
+	return mux
+}
+
+// readOnly wraps `h` to respond 405 to methods other than GET and HEAD.
+func readOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeJSON(w, http.StatusMethodNotAllowed, errorResult{"method not allowed: " + r.Method})
+			return
+		}
+		h(w, r)
+	}
+}
+
+// table returns the table specified by query argument `index`.
+// It can be omitted if there is only one table.
+func (s *server) table(w http.ResponseWriter, r *http.Request) (*table, bool) {
+	name := r.URL.Query().Get("index")
+	if name == "" && len(s.names) == 1 {
+		name = s.names[0]
+	}
+
+	t, ok := s.tables[name]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResult{"unknown index: " + name})
+		return nil, false
+	}
+	return t, true
+}
+
+func (s *server) handleGet(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.table(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	if _, ok := q["key"]; !ok {
+		writeJSON(w, http.StatusBadRequest, errorResult{"key is required"})
+		return
+	}
+	key := q.Get("key")
+
+	start := time.Now()
+	v, found := t.get(key)
+	s.getLatency.record(time.Since(start))
+
+	s.mu.Lock()
+	if found {
+		s.found++
+	} else {
+		s.notFound++
+	}
+	s.mu.Unlock()
+
+	if !found {
+		writeJSON(w, http.StatusNotFound, getResult{Key: key})
+		return
+	}
+	writeJSON(w, http.StatusOK, getResult{Key: key, Value: v, Found: true})
+}
+
+func (s *server) handleScan(w http.ResponseWriter, r *http.Request) {
+	t, ok := s.table(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+
+	limit := defaultScanLimit
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 0 || n > maxScanLimit {
+			writeJSON(w, http.StatusBadRequest, errorResult{"invalid limit: " + l})
+			return
+		}
+		limit = n
+	}
+
+	start := time.Now()
+	items := t.scan(q.Get("from"), q.Get("to"), limit)
+	s.scanLatency.record(time.Since(start))
+
+	writeJSON(w, http.StatusOK, scanResult{Items: items})
+}
+
+func (s *server) handleStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	st := statsResult{
+		Indexes:  s.names,
+		Found:    s.found,
+		NotFound: s.notFound,
+	}
+	s.mu.Unlock()
+
+	st.Get = s.getLatency.stats()
+	st.Scan = s.scanLatency.stats()
+
+	writeJSON(w, http.StatusOK, st)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// makeTestDir creates a temporary dir, the caller removes it.
+func makeTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "slim-serve-")
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	return dir
+}
+
+// makeTestTable writes a data file of `keys` in `dir`, builds and loads its
+// index.
+// The caller closes the returned table.
+func makeTestTable(t *testing.T, dir, name string, keys []string) *table {
+
+	dataPath := filepath.Join(dir, name+".tsv")
+	indexPath := filepath.Join(dir, name+".idx")
+
+	lines := []string{}
+	for _, k := range keys {
+		lines = append(lines, k+"\t"+strings.ToUpper(k)+"\n")
+	}
+	if err := ioutil.WriteFile(dataPath, []byte(strings.Join(lines, "")), 0644); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	if err := buildIndex(dataPath, indexPath); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	tb, err := loadTable(name, indexPath, dataPath)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	return tb
+}
+
+func getJSON(t *testing.T, srv *httptest.Server, path string, v interface{}) int {
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expect json but: %s", ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	return resp.StatusCode
+}
+
+func TestServer(t *testing.T) {
+
+	words := []string{"apple", "apply", "banana", "band", "can", "cat", "dog"}
+	nums := []string{}
+	for i := 0; i < 500; i++ {
+		nums = append(nums, fmt.Sprintf("%04d", i*2))
+	}
+
+	dir := makeTestDir(t)
+	defer os.RemoveAll(dir)
+
+	wordsTable := makeTestTable(t, dir, "words", words)
+	defer wordsTable.Close()
+	numsTable := makeTestTable(t, dir, "nums", nums)
+	defer numsTable.Close()
+
+	srv := httptest.NewServer(newServer([]*table{wordsTable, numsTable}).Handler())
+	defer srv.Close()
+
+	// get
+
+	getCases := []struct {
+		index, key string
+		code       int
+		want       getResult
+	}{
+		{"words", "apple", 200, getResult{"apple", "APPLE", true}},
+		{"words", "dog", 200, getResult{"dog", "DOG", true}},
+		{"words", "ban", 404, getResult{"ban", "", false}},
+		{"words", "zoo", 404, getResult{"zoo", "", false}},
+		{"words", "", 404, getResult{"", "", false}},
+		{"nums", "0998", 200, getResult{"0998", "0998", true}},
+		{"nums", "0999", 404, getResult{"0999", "", false}},
+	}
+
+	for i, c := range getCases {
+		var rst getResult
+		q := url.Values{"index": {c.index}, "key": {c.key}}
+		code := getJSON(t, srv, "/get?"+q.Encode(), &rst)
+		if code != c.code || rst != c.want {
+			t.Fatalf("%d-th: input: %v %q; want: %d %v; actual: %d %v",
+				i+1, c.index, c.key, c.code, c.want, code, rst)
+		}
+	}
+
+	// scan
+
+	scanKeys := func(items []record) []string {
+		keys := []string{}
+		for _, it := range items {
+			keys = append(keys, it.Key)
+		}
+		return keys
+	}
+
+	scanCases := []struct {
+		index, from, to, limit string
+		want                   []string
+	}{
+		{"words", "", "", "", words},
+		{"words", "b", "cat", "", []string{"banana", "band", "can"}},
+		{"words", "apply", "apply", "", []string{}},
+		{"words", "apply", "", "2", []string{"apply", "banana"}},
+		{"words", "bana", "z", "", []string{"banana", "band", "can", "cat", "dog"}},
+		{"words", "e", "", "", []string{}},
+		{"nums", "0101", "0108", "", []string{"0102", "0104", "0106"}},
+		{"nums", "0997", "", "", []string{"0998"}},
+	}
+
+	for i, c := range scanCases {
+		var rst scanResult
+		q := url.Values{"index": {c.index}, "from": {c.from}, "to": {c.to}, "limit": {c.limit}}
+		code := getJSON(t, srv, "/scan?"+q.Encode(), &rst)
+		if code != 200 || !reflect.DeepEqual(c.want, scanKeys(rst.Items)) {
+			t.Fatalf("%d-th: input: %v %q %q %q; want: %v; actual: %d %v",
+				i+1, c.index, c.from, c.to, c.limit, c.want, code, scanKeys(rst.Items))
+		}
+	}
+
+	// bad requests
+
+	var errRst errorResult
+	for _, path := range []string{
+		"/get?key=apple",
+		"/get?index=foo&key=apple",
+		"/get?index=words",
+		"/scan?index=words&limit=x",
+	} {
+		code := getJSON(t, srv, path, &errRst)
+		if code/100 != 4 || errRst.Error == "" {
+			t.Fatalf("path: %s; expect error but: %d %v", path, code, errRst)
+		}
+	}
+
+	// stats
+
+	var st statsResult
+	if code := getJSON(t, srv, "/stats", &st); code != 200 {
+		t.Fatalf("expect 200 but: %d", code)
+	}
+	if !reflect.DeepEqual([]string{"nums", "words"}, st.Indexes) {
+		t.Fatalf("expect index names but: %v", st.Indexes)
+	}
+	if st.Found != 3 || st.NotFound != 4 {
+		t.Fatalf("expect 3 found and 4 not found but: %v", st)
+	}
+	if st.Get.Count != int64(len(getCases)) || st.Scan.Count != int64(len(scanCases)) {
+		t.Fatalf("expect latency counts but: %v", st)
+	}
+	if st.Get.Max <= 0 || st.Get.P99 > st.Get.Max || st.Get.P50 > st.Get.P99 {
+		t.Fatalf("expect valid latency stats but: %v", st.Get)
+	}
+}
+
+func TestServerSingleIndex(t *testing.T) {
+
+	dir := makeTestDir(t)
+	defer os.RemoveAll(dir)
+
+	tb := makeTestTable(t, dir, "words", []string{"a", "b"})
+	defer tb.Close()
+
+	srv := httptest.NewServer(newServer([]*table{tb}).Handler())
+	defer srv.Close()
+
+	var rst getResult
+	code := getJSON(t, srv, "/get?key=b", &rst)
+	if code != 200 || rst.Value != "B" {
+		t.Fatalf("expect B but: %d %v", code, rst)
+	}
+}
+
+func TestServerMethods(t *testing.T) {
+
+	dir := makeTestDir(t)
+	defer os.RemoveAll(dir)
+
+	tb := makeTestTable(t, dir, "words", []string{"a", "b"})
+	defer tb.Close()
+
+	srv := httptest.NewServer(newServer([]*table{tb}).Handler())
+	defer srv.Close()
+
+	for _, path := range []string{"/get?key=b", "/scan", "/stats"} {
+		for _, c := range []struct {
+			method string
+			code   int
+		}{
+			{"GET", 200},
+			{"HEAD", 200},
+			{"POST", 405},
+			{"PUT", 405},
+			{"DELETE", 405},
+		} {
+			req, err := http.NewRequest(c.method, srv.URL+path, nil)
+			if err != nil {
+				t.Fatalf("expect no error but: %s", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("expect no error but: %s", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != c.code {
+				t.Fatalf("%s %s: expect %d but: %d", c.method, path, c.code, resp.StatusCode)
+			}
+			if c.code == 405 && resp.Header.Get("Allow") != "GET, HEAD" {
+				t.Fatalf("%s %s: expect Allow header but: %q", c.method, path, resp.Header.Get("Allow"))
+			}
+		}
+	}
+}
+
+func TestBuildIndexUnsorted(t *testing.T) {
+
+	dir := makeTestDir(t)
+	defer os.RemoveAll(dir)
+
+	dataPath := filepath.Join(dir, "d.tsv")
+	if err := ioutil.WriteFile(dataPath, []byte("b\t1\na\t2\n"), 0644); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	err := buildIndex(dataPath, filepath.Join(dir, "d.idx"))
+	if err != ErrKeysNotSorted {
+		t.Fatalf("expect ErrKeysNotSorted but: %v", err)
+	}
+}
+
+// countingReaderAt counts ReadAt calls.
+type countingReaderAt struct {
+	io.ReaderAt
+	mu    sync.Mutex
+	reads int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	r.reads++
+	r.mu.Unlock()
+	return r.ReaderAt.ReadAt(p, off)
+}
+
+func TestTableScan(t *testing.T) {
+
+	// SlimTrie skips words shared by "ca", "caa", "cb" and "cbb", thus it
+	// routes "cR" and "cZ" to a wrong record without reading a key.
+	keys := []string{"aa", "cR", "ca", "caa", "cb", "cbb"}
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, fmt.Sprintf("d%04d", i))
+	}
+
+	dir := makeTestDir(t)
+	defer os.RemoveAll(dir)
+
+	tb := makeTestTable(t, dir, "keys", keys)
+	defer tb.Close()
+
+	cr := &countingReaderAt{ReaderAt: tb.data.ReaderAt}
+	tb.data.ReaderAt = cr
+
+	cases := []struct {
+		from, to string
+		limit    int
+		want     []string
+	}{
+		{"", "b", 10, []string{"aa"}},
+		{"b", "caa", 10, []string{"cR", "ca"}},
+		{"cR", "", 2, []string{"cR", "ca"}},
+		{"cS", "cb", 10, []string{"ca", "caa"}},
+		{"cZ", "", 3, []string{"ca", "caa", "cb"}},
+		{"caaa", "d", 10, []string{"cb", "cbb"}},
+		{"cbc", "", 1, []string{"d0000"}},
+		{"d0500", "", 0, []string{}},
+		{"d09995", "", 10, []string{}},
+		{"e", "", 10, []string{}},
+	}
+
+	for i, c := range cases {
+		cr.reads = 0
+
+		rst := []string{}
+		for _, r := range tb.scan(c.from, c.to, c.limit) {
+			rst = append(rst, r.Key)
+		}
+		if !reflect.DeepEqual(c.want, rst) {
+			t.Fatalf("%d-th: input: %q %q %d; want: %v; actual: %v",
+				i+1, c.from, c.to, c.limit, c.want, rst)
+		}
+
+		// a key read by Seek, and at most limit+1 records
+		if cr.reads > c.limit+2 {
+			t.Fatalf("%d-th: expect at most %d reads but: %d", i+1, c.limit+2, cr.reads)
+		}
+	}
+}
+
+func TestTableConcurrentGet(t *testing.T) {
+
+	keys := []string{}
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, fmt.Sprintf("k%04d", i))
+	}
+
+	dir := makeTestDir(t)
+	defer os.RemoveAll(dir)
+
+	tb := makeTestTable(t, dir, "keys", keys)
+	defer tb.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 8)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := g; i < len(keys); i += 8 {
+				v, found := tb.get(keys[i])
+				if !found || v != strings.ToUpper(keys[i]) {
+					errs <- keys[i]
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+
+	for k := range errs {
+		t.Fatalf("expect %q to be found", k)
+	}
+}
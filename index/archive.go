@@ -0,0 +1,365 @@
+package index
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNotZip indicates there is no valid zip central directory.
+	ErrNotZip = errors.New("not a zip archive")
+	// ErrZip64NotSupported indicates a zip archive requires zip64 extension.
+	ErrZip64NotSupported = errors.New("zip64 archive is not supported")
+	// ErrZipMethodNotSupported indicates a zip member is compressed with a
+	// method other than store or deflate.
+	ErrZipMethodNotSupported = errors.New("zip compression method is not supported")
+)
+
+const (
+	tarBlockSize = 512
+
+	zipEOCDSig       = 0x06054b50
+	zipEOCDLen       = 22
+	zip64LocatorSig  = 0x07064b50
+	zip64LocatorLen  = 20
+	zip64ExtraID     = 0x0001
+	zipCentralSig    = 0x02014b50
+	zipCentralLen    = 46
+	zipLocalSig      = 0x04034b50
+	zipLocalLen      = 30
+	zipMaxCommentLen = 65535
+
+	zipStore   = 0
+	zipDeflate = 8
+)
+
+// NewTarIndex creates a SlimIndex of regular file members in a tar archive, by
+// member name.
+// The DataReader of it is a TarReader on `ra`, which returns member contents.
+//
+// The index can be stored next to the archive with `SlimIndex.Marshal`, and be
+// loaded with `SlimIndex.Unmarshal` on `NewSlimIndex(nil, TarReader{ra})`.
+func NewTarIndex(ra io.ReaderAt, size int64, opts ...Option) (*SlimIndex, error) {
+	items, err := ScanTar(io.NewSectionReader(ra, 0, size))
+	if err != nil {
+		return nil, err
+	}
+	return NewSlimIndex(items, TarReader{ra}, opts...)
+}
+
+// NewZipIndex creates a SlimIndex of file members in a zip archive, by member
+// name.
+// The DataReader of it is a ZipReader on `ra`, which returns member contents.
+//
+// The index can be stored next to the archive with `SlimIndex.Marshal`, and be
+// loaded with `SlimIndex.Unmarshal` on `NewSlimIndex(nil, ZipReader{ra})`.
+func NewZipIndex(ra io.ReaderAt, size int64, opts ...Option) (*SlimIndex, error) {
+	items, err := ScanZip(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	return NewSlimIndex(items, ZipReader{ra}, opts...)
+}
+
+// countingReader counts bytes read from the underlying reader.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// ScanTar reads a tar stream and returns index items of regular file members,
+// sorted by name.
+// The offset of an item is where the headers of the member start.
+//
+// If a name presents more than once, the last member wins, as tar extracting
+// does.
+func ScanTar(r io.Reader) ([]OffsetIndexItem, error) {
+
+	cr := &countingReader{Reader: r}
+	tr := tar.NewReader(cr)
+
+	items := []OffsetIndexItem{}
+
+	// start of the headers of the next member.
+	next := int64(0)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hdr.FileInfo().Mode().IsRegular() {
+			items = append(items, OffsetIndexItem{Key: hdr.Name, Offset: next})
+		}
+
+		// hdr.Size is the logical size of a sparse member, not the bytes stored
+		// in the archive, thus the data is drained to find where it ends.
+		if _, err := io.Copy(ioutil.Discard, tr); err != nil {
+			return nil, err
+		}
+		next = (cr.n + tarBlockSize - 1) / tarBlockSize * tarBlockSize
+	}
+
+	return sortItemsKeepLast(items), nil
+}
+
+// ScanZip reads the central directory of a zip archive of `size` bytes and
+// returns index items of file members, sorted by name.
+// The offset of an item is where the central directory header of the member
+// is.
+//
+// Zip64 archives are not supported: it returns ErrZip64NotSupported if there
+// is a zip64 end of central directory, or a member has a zip64 extra field or
+// a size or offset that does not fit in 32 bits.
+func ScanZip(ra io.ReaderAt, size int64) ([]OffsetIndexItem, error) {
+
+	eocdOffset, eocd, err := findZipEOCD(ra, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if eocdOffset >= zip64LocatorLen {
+		loc := make([]byte, 4)
+		if _, err := ra.ReadAt(loc, eocdOffset-zip64LocatorLen); err != nil {
+			return nil, err
+		}
+		if binary.LittleEndian.Uint32(loc) == zip64LocatorSig {
+			return nil, ErrZip64NotSupported
+		}
+	}
+
+	cnt := binary.LittleEndian.Uint16(eocd[10:])
+	cdSize := binary.LittleEndian.Uint32(eocd[12:])
+	cdOffset := binary.LittleEndian.Uint32(eocd[16:])
+	if cnt == math.MaxUint16 || cdSize == math.MaxUint32 || cdOffset == math.MaxUint32 {
+		return nil, ErrZip64NotSupported
+	}
+
+	items := []OffsetIndexItem{}
+
+	p := int64(cdOffset)
+	for i := 0; i < int(cnt); i++ {
+		hdr, name, err := readZipCentral(ra, p)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasSuffix(name, "/") {
+			method := binary.LittleEndian.Uint16(hdr[10:])
+			if method != zipStore && method != zipDeflate {
+				return nil, ErrZipMethodNotSupported
+			}
+			zip64, err := isZip64Central(ra, p, hdr, len(name))
+			if err != nil {
+				return nil, err
+			}
+			if zip64 {
+				return nil, ErrZip64NotSupported
+			}
+			items = append(items, OffsetIndexItem{Key: name, Offset: p})
+		}
+
+		p += zipCentralLen + int64(len(name)) +
+			int64(binary.LittleEndian.Uint16(hdr[30:])) +
+			int64(binary.LittleEndian.Uint16(hdr[32:]))
+	}
+
+	return sortItemsKeepLast(items), nil
+}
+
+// sortItemsKeepLast sorts items by key and removes all but the last item of
+// the same key.
+func sortItemsKeepLast(items []OffsetIndexItem) []OffsetIndexItem {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+
+	rst := items[:0]
+	for i, it := range items {
+		if i+1 < len(items) && items[i+1].Key == it.Key {
+			continue
+		}
+		rst = append(rst, it)
+	}
+	return rst
+}
+
+// isZip64Central returns true if the central directory header `hdr` at
+// `offset`, of a name of `nameLen` bytes, requires zip64 extension:
+// a saturated size, offset or disk number, or a zip64 extra field.
+func isZip64Central(ra io.ReaderAt, offset int64, hdr []byte, nameLen int) (bool, error) {
+
+	if binary.LittleEndian.Uint32(hdr[20:]) == math.MaxUint32 ||
+		binary.LittleEndian.Uint32(hdr[24:]) == math.MaxUint32 ||
+		binary.LittleEndian.Uint16(hdr[34:]) == math.MaxUint16 ||
+		binary.LittleEndian.Uint32(hdr[42:]) == math.MaxUint32 {
+		return true, nil
+	}
+
+	extra := make([]byte, binary.LittleEndian.Uint16(hdr[30:]))
+	if _, err := ra.ReadAt(extra, offset+zipCentralLen+int64(nameLen)); err != nil {
+		return false, err
+	}
+
+	// extra fields: 2-byte id, 2-byte size and data.
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		l := int(binary.LittleEndian.Uint16(extra[2:]))
+		if id == zip64ExtraID {
+			return true, nil
+		}
+		if 4+l > len(extra) {
+			break
+		}
+		extra = extra[4+l:]
+	}
+
+	return false, nil
+}
+
+// findZipEOCD returns the offset and the content of the end of central
+// directory record.
+func findZipEOCD(ra io.ReaderAt, size int64) (int64, []byte, error) {
+
+	l := int64(zipEOCDLen + zipMaxCommentLen)
+	if l > size {
+		l = size
+	}
+	if l < zipEOCDLen {
+		return 0, nil, ErrNotZip
+	}
+
+	// ReadAt might return io.EOF with all bytes read at the end of archive.
+	buf := make([]byte, l)
+	n, err := ra.ReadAt(buf, size-l)
+	if n != len(buf) {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, nil, err
+	}
+
+	for i := len(buf) - zipEOCDLen; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(buf[i:]) == zipEOCDSig {
+			return size - l + int64(i), buf[i : i+zipEOCDLen], nil
+		}
+	}
+
+	return 0, nil, ErrNotZip
+}
+
+// readZipCentral reads the fixed size part and the name of a central
+// directory header at `offset`.
+func readZipCentral(ra io.ReaderAt, offset int64) ([]byte, string, error) {
+
+	hdr := make([]byte, zipCentralLen)
+	if _, err := ra.ReadAt(hdr, offset); err != nil {
+		return nil, "", err
+	}
+	if binary.LittleEndian.Uint32(hdr) != zipCentralSig {
+		return nil, "", ErrNotZip
+	}
+
+	name := make([]byte, binary.LittleEndian.Uint16(hdr[28:]))
+	if _, err := ra.ReadAt(name, offset+zipCentralLen); err != nil {
+		return nil, "", err
+	}
+
+	return hdr, string(name), nil
+}
+
+// TarReader implements DataReader for a tar archive indexed by ScanTar.
+// It returns the contents of a member.
+type TarReader struct {
+	io.ReaderAt
+}
+
+// Read reads the member headers at `offset`, and returns the contents of the
+// member if its name is `key`.
+func (r TarReader) Read(offset int64, key string) (string, bool) {
+
+	tr := tar.NewReader(io.NewSectionReader(r.ReaderAt, offset, math.MaxInt64-offset))
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != key {
+		return "", false
+	}
+
+	b, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// ZipReader implements DataReader for a zip archive indexed by ScanZip.
+// It returns the contents of a member, decompressed if it is deflated.
+type ZipReader struct {
+	io.ReaderAt
+}
+
+// Read reads the central directory header at `offset`, and returns the
+// contents of the member if its name is `key`.
+func (r ZipReader) Read(offset int64, key string) (string, bool) {
+
+	hdr, name, err := readZipCentral(r.ReaderAt, offset)
+	if err != nil || name != key {
+		return "", false
+	}
+
+	method := binary.LittleEndian.Uint16(hdr[10:])
+	compressed := binary.LittleEndian.Uint32(hdr[20:])
+	localOffset := int64(binary.LittleEndian.Uint32(hdr[42:]))
+	if compressed == math.MaxUint32 || localOffset == math.MaxUint32 {
+		// zip64, rejected by ScanZip
+		return "", false
+	}
+
+	// Sizes in local header might be 0 if a data descriptor is used.
+	// Use sizes in central directory header.
+	local := make([]byte, zipLocalLen)
+	if _, err := r.ReadAt(local, localOffset); err != nil {
+		return "", false
+	}
+	if binary.LittleEndian.Uint32(local) != zipLocalSig {
+		return "", false
+	}
+	dataOffset := localOffset + zipLocalLen +
+		int64(binary.LittleEndian.Uint16(local[26:])) +
+		int64(binary.LittleEndian.Uint16(local[28:]))
+
+	// ReadAt might return io.EOF with all bytes read at the end of archive.
+	data := make([]byte, compressed)
+	if n, _ := r.ReadAt(data, dataOffset); n != len(data) {
+		return "", false
+	}
+
+	switch method {
+	case zipStore:
+		return string(data), true
+	case zipDeflate:
+		b, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,350 @@
+package index_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/index"
+)
+
+var testArchiveMembers = []struct {
+	name    string
+	content string
+}{
+	{"a.txt", "aaa"},
+	{"dir/b.txt", strings.Repeat("b", 1000)},
+	{"dir/empty", ""},
+	{"dir/" + strings.Repeat("long", 40) + ".txt", "long name"},
+	{"z/c.txt", strings.Repeat("c", 512)},
+}
+
+func testArchiveCases(t *testing.T, si *index.SlimIndex) {
+
+	for _, m := range testArchiveMembers {
+		rst, found := si.Get2(m.name)
+		if !found || rst != m.content {
+			t.Fatalf("input: %q; want: %d bytes; actual: %d bytes %v",
+				m.name, len(m.content), len(rst), found)
+		}
+	}
+
+	for _, name := range []string{"", "a", "a.tx", "dir", "dir/", "dir/b.txt0", "zz"} {
+		if _, found := si.Get2(name); found {
+			t.Fatalf("input: %q; expect not found", name)
+		}
+	}
+}
+
+func TestTarIndex(t *testing.T) {
+
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+
+	write := func(hdr *tar.Header, content string) {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+	}
+
+	write(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755}, "")
+	// overridden by the member with the same name below
+	write(&tar.Header{Name: "a.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: 3}, "old")
+	for i := len(testArchiveMembers) - 1; i >= 0; i-- {
+		m := testArchiveMembers[i]
+		write(&tar.Header{
+			Name:     m.name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(m.content)),
+		}, m.content)
+	}
+	write(&tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "a.txt"}, "")
+
+	if err := tw.Close(); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	archive := bytes.NewReader(buf.Bytes())
+
+	items, err := index.ScanTar(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if len(items) != len(testArchiveMembers) {
+		t.Fatalf("expect %d items but: %v", len(testArchiveMembers), items)
+	}
+
+	si, err := index.NewTarIndex(archive, archive.Size())
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	testArchiveCases(t, si)
+
+	// persist the index next to the archive
+
+	dir, err := ioutil.TempDir("", "slim-archive-")
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	idxPath := filepath.Join(dir, "foo.tar.idx")
+	f, err := os.Create(idxPath)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if _, err := si.Marshal(f); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	f, err = os.Open(idxPath)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	defer f.Close()
+
+	loaded, err := index.NewSlimIndex(nil, index.TarReader{ReaderAt: archive})
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if err := loaded.Unmarshal(f); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	testArchiveCases(t, loaded)
+
+	// broken tar
+
+	_, err = index.ScanTar(bytes.NewReader(bytes.Repeat([]byte("x"), 1024)))
+	if err == nil {
+		t.Fatalf("expect error but nil")
+	}
+}
+
+// gnuSparseMember returns an old GNU sparse member of `size` bytes, in which
+// only `data` at `offset` is stored.
+func gnuSparseMember(name string, size, offset int64, data string) []byte {
+
+	hdr := make([]byte, 512)
+	copy(hdr[0:], name)
+	copy(hdr[100:], "0000644\x00")
+	copy(hdr[108:], "0000000\x00")
+	copy(hdr[116:], "0000000\x00")
+	copy(hdr[124:], fmt.Sprintf("%011o\x00", len(data)))
+	copy(hdr[136:], "00000000000\x00")
+	hdr[156] = tar.TypeGNUSparse
+	copy(hdr[257:], "ustar  \x00")
+
+	// the only sparse entry and the logical size
+	copy(hdr[386:], fmt.Sprintf("%011o\x00", offset))
+	copy(hdr[398:], fmt.Sprintf("%011o\x00", len(data)))
+	copy(hdr[483:], fmt.Sprintf("%011o\x00", size))
+
+	copy(hdr[148:], "        ")
+	sum := 0
+	for _, b := range hdr {
+		sum += int(b)
+	}
+	copy(hdr[148:], fmt.Sprintf("%06o\x00 ", sum))
+
+	body := make([]byte, (len(data)+511)/512*512)
+	copy(body, data)
+
+	return append(hdr, body...)
+}
+
+func TestTarIndexSparse(t *testing.T) {
+
+	buf := new(bytes.Buffer)
+	buf.Write(gnuSparseMember("sparse", 4096, 2048, "hello"))
+
+	tw := tar.NewWriter(buf)
+	for _, m := range testArchiveMembers {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     m.name,
+			Typeflag: tar.TypeReg,
+			Mode:     0644,
+			Size:     int64(len(m.content)),
+		}); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		if _, err := tw.Write([]byte(m.content)); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	archive := bytes.NewReader(buf.Bytes())
+
+	si, err := index.NewTarIndex(archive, archive.Size())
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	// members after the sparse one
+	testArchiveCases(t, si)
+
+	want := string(make([]byte, 2048)) + "hello" + string(make([]byte, 4096-2048-5))
+	rst, found := si.Get2("sparse")
+	if !found || rst != want {
+		t.Fatalf("expect sparse member of %d bytes but: %d bytes %v",
+			len(want), len(rst), found)
+	}
+}
+
+func TestZipIndex(t *testing.T) {
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	if _, err := zw.Create("dir/"); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	for i, m := range testArchiveMembers {
+		method := zip.Deflate
+		if i%2 == 0 {
+			method = zip.Store
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: m.name, Method: method})
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		if _, err := w.Write([]byte(m.content)); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	// append a comment: the last 2 bytes of EOCD are the comment length.
+	b := buf.Bytes()
+	binary.LittleEndian.PutUint16(b[len(b)-2:], uint16(len("comment")))
+	b = append(b, "comment"...)
+
+	archive := bytes.NewReader(b)
+
+	si, err := index.NewZipIndex(archive, archive.Size(), index.CompressOffsets())
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	testArchiveCases(t, si)
+
+	idx := new(bytes.Buffer)
+	if _, err := si.Marshal(idx); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	loaded, err := index.NewSlimIndex(nil, index.ZipReader{ReaderAt: archive}, index.CompressOffsets())
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if err := loaded.Unmarshal(idx); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	testArchiveCases(t, loaded)
+
+	// a stored member whose data runs past the end of the archive
+
+	broken := append([]byte{}, b...)
+	last := testArchiveMembers[len(testArchiveMembers)-1]
+	cd := bytes.LastIndex(broken, []byte(last.name)) - 46
+	if binary.LittleEndian.Uint32(broken[cd:]) != 0x02014b50 {
+		t.Fatalf("expect central directory header of %q", last.name)
+	}
+	binary.LittleEndian.PutUint32(broken[cd+20:], uint32(len(broken)))
+	binary.LittleEndian.PutUint32(broken[cd+24:], uint32(len(broken)))
+
+	brokenSI, err := index.NewZipIndex(bytes.NewReader(broken), int64(len(broken)))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if rst, found := brokenSI.Get2(last.name); found {
+		t.Fatalf("expect not found for a truncated member but: %d bytes", len(rst))
+	}
+
+	// truncated archive
+
+	truncated := bytes.NewReader(b[:len(b)-10])
+	if _, err := index.ScanZip(truncated, int64(len(b))); err == nil {
+		t.Fatalf("expect error with truncated archive but nil")
+	}
+
+	// not a zip
+
+	notZip := bytes.NewReader([]byte("not a zip archive at all"))
+	_, err = index.ScanZip(notZip, notZip.Size())
+	if err != index.ErrNotZip {
+		t.Fatalf("expect ErrNotZip but: %v", err)
+	}
+}
+
+func TestZipIndexZip64(t *testing.T) {
+
+	build := func(extra []byte) []byte {
+		buf := new(bytes.Buffer)
+		zw := zip.NewWriter(buf)
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: "a.txt", Method: zip.Store, Extra: extra})
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		if _, err := w.Write([]byte("aaa")); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		return buf.Bytes()
+	}
+
+	// offset of the central directory header of the only member
+	centralOf := func(b []byte) int {
+		eocd := len(b) - 22
+		return int(binary.LittleEndian.Uint32(b[eocd+16:]))
+	}
+
+	plain := build(nil)
+	if _, err := index.ScanZip(bytes.NewReader(plain), int64(len(plain))); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	cases := map[string][]byte{}
+
+	// zip64 extra field
+	cases["extra"] = build([]byte{0x01, 0x00, 0x00, 0x00})
+
+	// saturated sizes and offsets in the central directory header
+	for name, pos := range map[string]int{"compressed": 20, "uncompressed": 24, "local offset": 42} {
+		b := append([]byte{}, plain...)
+		binary.LittleEndian.PutUint32(b[centralOf(b)+pos:], 0xffffffff)
+		cases[name] = b
+	}
+
+	// zip64 end of central directory locator before EOCD
+	eocd := len(plain) - 22
+	loc := make([]byte, 20)
+	binary.LittleEndian.PutUint32(loc, 0x07064b50)
+	withLoc := append(append(append([]byte{}, plain[:eocd]...), loc...), plain[eocd:]...)
+	cases["locator"] = withLoc
+
+	for name, b := range cases {
+		_, err := index.ScanZip(bytes.NewReader(b), int64(len(b)))
+		if err != index.ErrZip64NotSupported {
+			t.Fatalf("%s: expect ErrZip64NotSupported but: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package index
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultBatchParallel is the default max number of concurrent reads
+	// GetBatch issues.
+	DefaultBatchParallel = 8
+
+	// maxCoalescedRead is the max size in byte of a read that is merged from
+	// several record reads.
+	maxCoalescedRead = int64(1024 * 1024)
+)
+
+// BatchReader is a DataReader that also provides access to the raw data, so
+// that GetBatch is able to read many records with a few large ReadAt calls.
+type BatchReader interface {
+	DataReader
+	io.ReaderAt
+
+	// MaxRecordSize returns the max size in byte of a record.
+	MaxRecordSize() int64
+
+	// Decode is similar to Read except it parses the record at the start of
+	// `b`.
+	// `b` might be shorter than MaxRecordSize() at the end of data.
+	Decode(b []byte, key string) (string, bool)
+}
+
+// BatchParallel sets the max number of concurrent reads GetBatch issues.
+// By default it is DefaultBatchParallel.
+func BatchParallel(n int) Option {
+	return func(si *SlimIndex) {
+		si.batchParallel = n
+	}
+}
+
+// batchRead is a read of several records.
+type batchRead struct {
+	start, end int64
+	// indexes of keys whose record is in this read.
+	keys []int
+}
+
+// GetBatch returns values of `keys`, and bools indicating if every key is
+// found, in the same order as `keys`.
+//
+// It resolves all offsets through SlimTrie first.
+// If `SlimIndex.DataReader` is a BatchReader, records that are adjacent or
+// overlapped are read with one ReadAt call.
+// Otherwise it calls `DataReader.Read` for every key.
+// Reads are issued concurrently, at most `BatchParallel` at a time.
+//
+// An error is returned if a ReadAt fails.
+func (si *SlimIndex) GetBatch(keys []string) ([]string, []bool, error) {
+
+	vals := make([]string, len(keys))
+	found := make([]bool, len(keys))
+
+	br, ok := asBatchReader(si.DataReader)
+	if !ok || si.blockSize > 1 {
+		si.getBatchByRead(keys, vals, found)
+		return vals, found, nil
+	}
+
+	offsets := make([]int64, len(keys))
+	sorted := make([]int, 0, len(keys))
+	for i, k := range keys {
+		o, ok := si.getOffset(k)
+		if ok {
+			offsets[i] = o
+			sorted = append(sorted, i)
+		}
+	}
+
+	sort.Slice(sorted, func(a, b int) bool {
+		return offsets[sorted[a]] < offsets[sorted[b]]
+	})
+
+	recSize := br.MaxRecordSize()
+	reads := []*batchRead{}
+	var r *batchRead
+	for _, i := range sorted {
+		start, end := offsets[i], offsets[i]+recSize
+		if r != nil && start <= r.end && end-r.start <= maxCoalescedRead {
+			if end > r.end {
+				r.end = end
+			}
+			r.keys = append(r.keys, i)
+			continue
+		}
+		r = &batchRead{start: start, end: end, keys: []int{i}}
+		reads = append(reads, r)
+	}
+
+	var mu sync.Mutex
+	var firstErr error
+
+	si.parallel(len(reads), func(j int) {
+		r := reads[j]
+		buf := make([]byte, r.end-r.start)
+		n, err := br.ReadAt(buf, r.start)
+		if err != nil && err != io.EOF {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = err
+			}
+			mu.Unlock()
+			return
+		}
+		buf = buf[:n]
+
+		for _, i := range r.keys {
+			p := offsets[i] - r.start
+			if p >= int64(n) {
+				continue
+			}
+			vals[i], found[i] = br.Decode(buf[p:], keys[i])
+		}
+	})
+
+	return vals, found, firstErr
+}
+
+// getBatchByRead reads `keys` with `DataReader.Read`, or with the block lookup
+// of Get2 in block mode.
+func (si *SlimIndex) getBatchByRead(keys []string, vals []string, found []bool) {
+
+	si.parallel(len(keys), func(i int) {
+		if si.blockSize > 1 {
+			vals[i], found[i] = si.getFromBlock(keys[i])
+			return
+		}
+
+		o, ok := si.getOffset(keys[i])
+		if ok {
+			vals[i], found[i] = si.DataReader.Read(o, keys[i])
+		}
+	})
+}
+
+// parallel calls `fn` with 0 to n-1, in at most `batchParallel` go-routines.
+func (si *SlimIndex) parallel(n int, fn func(i int)) {
+
+	p := si.batchParallel
+	if p <= 0 {
+		p = DefaultBatchParallel
+	}
+	if p > n {
+		p = n
+	}
+
+	ch := make(chan int, n)
+	for i := 0; i < n; i++ {
+		ch <- i
+	}
+	close(ch)
+
+	var wg sync.WaitGroup
+	for w := 0; w < p; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ch {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
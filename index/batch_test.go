@@ -0,0 +1,149 @@
+package index_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openacid/slim/index"
+)
+
+// testBatchData is a BatchReader that counts ReadAt calls.
+type testBatchData struct {
+	testBlockData
+	readAts int64
+	err     error
+}
+
+func (d *testBatchData) ReadAt(b []byte, off int64) (int, error) {
+	atomic.AddInt64(&d.readAts, 1)
+	if d.err != nil {
+		return 0, d.err
+	}
+	return strings.NewReader(string(d.testBlockData)).ReadAt(b, off)
+}
+
+func (d *testBatchData) MaxRecordSize() int64 {
+	return 16
+}
+
+func (d *testBatchData) Decode(b []byte, key string) (string, bool) {
+	kv := strings.Split(string(b), ",")
+	if len(kv) >= 2 && kv[0] == key {
+		return kv[1], true
+	}
+	return "", false
+}
+
+func TestSlimIndexGetBatch(t *testing.T) {
+
+	n := 1000
+	blockData, items := makeTestBlockData(n)
+
+	keys := []string{}
+	for i := n - 1; i >= 0; i-- {
+		keys = append(keys, fmt.Sprintf("k%05d", i*7), fmt.Sprintf("k%05d", i*7+1))
+	}
+	// duplicate and absent keys
+	keys = append(keys, "k00000", "k00000", "foo", "")
+
+	data := &testBatchData{testBlockData: blockData}
+
+	for _, opts := range [][]index.Option{
+		{},
+		{index.BatchParallel(1)},
+		{index.BatchParallel(100)},
+		{index.CompressOffsets()},
+	} {
+
+		data.readAts = 0
+
+		st, err := index.NewSlimIndex(items, data, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		vals, found, err := st.GetBatch(keys)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for i, k := range keys {
+			want, wantfound := st.Get2(k)
+			if vals[i] != want || found[i] != wantfound {
+				t.Fatalf("input: %v; want: %v %v; actual: %v %v",
+					k, want, wantfound, vals[i], found[i])
+			}
+		}
+
+		// adjacent records are merged into a few reads
+		if data.readAts > int64(n/10) {
+			t.Fatalf("expect reads to be coalesced but: %d ReadAt", data.readAts)
+		}
+	}
+
+	// not a BatchReader, or in block mode
+
+	for _, opts := range [][]index.Option{
+		{},
+		{index.BlockSize(8)},
+	} {
+
+		st, err := index.NewSlimIndex(items, blockData, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		vals, found, err := st.GetBatch(keys)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for i, k := range keys {
+			want, wantfound := st.Get2(k)
+			if vals[i] != want || found[i] != wantfound {
+				t.Fatalf("input: %v; want: %v %v; actual: %v %v",
+					k, want, wantfound, vals[i], found[i])
+			}
+		}
+	}
+
+	// block mode with keys SlimTrie routes to a wrong block
+
+	skipped := []string{"aa", "cR", "ca", "caa", "cb", "cbb"}
+	skippedData, skippedItems := makeTestBlockDataOf(skipped)
+
+	st, err := index.NewSlimIndex(skippedItems, skippedData, index.BlockSize(2))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	vals, found, err := st.GetBatch(append(skipped, "cQ", "cq", "b"))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	for i := range skipped {
+		if !found[i] || vals[i] != fmt.Sprintf("%d", i) {
+			t.Fatalf("input: %v; want: %d; actual: %v %v", skipped[i], i, vals[i], found[i])
+		}
+	}
+	for i := len(skipped); i < len(found); i++ {
+		if found[i] {
+			t.Fatalf("%d-th: should not be found but: %v", i, vals[i])
+		}
+	}
+
+	// ReadAt error
+
+	data.err = errors.New("foo")
+	st, err = index.NewSlimIndex(items, data)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	_, _, err = st.GetBatch(keys)
+	if err != data.err {
+		t.Fatalf("expect error: %v but: %v", data.err, err)
+	}
+}
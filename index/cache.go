@@ -0,0 +1,307 @@
+package index
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+)
+
+// ErrNotBatchReader indicates a CachedReader is used as a BatchReader but the
+// DataReader it wraps is not one.
+var ErrNotBatchReader = errors.New("the wrapped DataReader is not a BatchReader")
+
+// cacheEntryOverhead is the approximate memory in byte a cache entry takes,
+// besides key and value.
+const cacheEntryOverhead = 64
+
+// CacheStats is statistics of a CachedReader.
+type CacheStats struct {
+	// Hits is the number of reads served by cache.
+	Hits int64
+	// Misses is the number of reads passed to the underlying DataReader.
+	Misses int64
+	// Evictions is the number of records or blocks removed from cache to make
+	// room.
+	Evictions int64
+	// Entries is the number of records and blocks in cache.
+	Entries int
+	// Bytes is the approximate size of all records and blocks in cache.
+	Bytes int64
+}
+
+// cacheEntry is a record read by Read, or a block of `n` records read by
+// ReadBlock.
+type cacheEntry struct {
+	offset int64
+	key    string
+	value  string
+
+	isBlock bool
+	n       int
+	keys    []string
+	values  []string
+}
+
+func (e *cacheEntry) size() int64 {
+	size := len(e.key) + len(e.value) + cacheEntryOverhead
+	for i := range e.keys {
+		size += len(e.keys[i]) + len(e.values[i])
+	}
+	return int64(size)
+}
+
+// CachedReader caches records read from another DataReader by offset, with a
+// least-recently-used eviction policy.
+//
+// Only found records are cached.
+// A read of a cached offset with a different key is passed to the underlying
+// DataReader.
+//
+// It has the methods of BlockReader, KeyValueReader and BatchReader, and
+// forwards them to the underlying DataReader.
+// A SlimIndex looks through a CachedReader and uses these methods only if the
+// underlying DataReader implements the interface.
+//
+// Blocks read by ReadBlock, which a SlimIndex in block mode uses, are cached by
+// block offset.
+// Thus all keys in a block are served by one cached block.
+// Records read by ReadKeyValue are cached by offset, as by Read.
+// Raw data read by ReadAt, which GetBatch uses, are not cached.
+//
+// It is safe for concurrent use.
+type CachedReader struct {
+	DataReader
+
+	maxBytes int64
+
+	mu        sync.Mutex
+	lru       *list.List
+	entries   map[int64]*list.Element
+	blocks    map[int64]*list.Element
+	bytes     int64
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+// NewCachedReader creates a CachedReader in front of `dr`, which caches at most
+// about `maxBytes` bytes of records.
+func NewCachedReader(dr DataReader, maxBytes int64) *CachedReader {
+	return &CachedReader{
+		DataReader: dr,
+		maxBytes:   maxBytes,
+		lru:        list.New(),
+		entries:    make(map[int64]*list.Element),
+		blocks:     make(map[int64]*list.Element),
+	}
+}
+
+// Read implements DataReader.
+func (c *CachedReader) Read(offset int64, key string) (string, bool) {
+
+	c.mu.Lock()
+	if el, ok := c.entries[offset]; ok {
+		e := el.Value.(*cacheEntry)
+		if e.key == key {
+			c.lru.MoveToFront(el)
+			c.hits++
+			c.mu.Unlock()
+			return e.value, true
+		}
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	v, found := c.DataReader.Read(offset, key)
+	if found {
+		c.add(&cacheEntry{offset: offset, key: key, value: v})
+	}
+
+	return v, found
+}
+
+// ReadKeyValue implements KeyValueReader.
+//
+// It returns nothing if the underlying DataReader is not a KeyValueReader.
+func (c *CachedReader) ReadKeyValue(offset int64) (string, string, bool) {
+
+	kvr, ok := c.DataReader.(KeyValueReader)
+	if !ok {
+		return "", "", false
+	}
+
+	c.mu.Lock()
+	if el, ok := c.entries[offset]; ok {
+		e := el.Value.(*cacheEntry)
+		c.lru.MoveToFront(el)
+		c.hits++
+		c.mu.Unlock()
+		return e.key, e.value, true
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	k, v, found := kvr.ReadKeyValue(offset)
+	if found {
+		c.add(&cacheEntry{offset: offset, key: k, value: v})
+	}
+
+	return k, v, found
+}
+
+// ReadAt implements BatchReader, and is not cached.
+//
+// It returns ErrNotBatchReader if the underlying DataReader is not a
+// BatchReader.
+func (c *CachedReader) ReadAt(b []byte, off int64) (int, error) {
+	br, ok := c.DataReader.(BatchReader)
+	if !ok {
+		return 0, ErrNotBatchReader
+	}
+	return br.ReadAt(b, off)
+}
+
+// MaxRecordSize implements BatchReader.
+func (c *CachedReader) MaxRecordSize() int64 {
+	br, ok := c.DataReader.(BatchReader)
+	if !ok {
+		return 0
+	}
+	return br.MaxRecordSize()
+}
+
+// Decode implements BatchReader.
+func (c *CachedReader) Decode(b []byte, key string) (string, bool) {
+	br, ok := c.DataReader.(BatchReader)
+	if !ok {
+		return "", false
+	}
+	return br.Decode(b, key)
+}
+
+// ReadBlock implements BlockReader.
+// The returned slices are shared with cache and must not be modified.
+//
+// It returns nothing if the underlying DataReader is not a BlockReader.
+func (c *CachedReader) ReadBlock(offset int64, n int) ([]string, []string) {
+
+	br, ok := c.DataReader.(BlockReader)
+	if !ok {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	if el, ok := c.blocks[offset]; ok {
+		e := el.Value.(*cacheEntry)
+		if e.n == n {
+			c.lru.MoveToFront(el)
+			c.hits++
+			c.mu.Unlock()
+			return e.keys, e.values
+		}
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	keys, values := br.ReadBlock(offset, n)
+	if len(keys) > 0 {
+		c.add(&cacheEntry{offset: offset, isBlock: true, n: n, keys: keys, values: values})
+	}
+
+	return keys, values
+}
+
+// entryMap returns the map `e` is indexed in.
+func (c *CachedReader) entryMap(e *cacheEntry) map[int64]*list.Element {
+	if e.isBlock {
+		return c.blocks
+	}
+	return c.entries
+}
+
+// add puts a record or a block into cache and evicts least recently used ones
+// if cache is full.
+func (c *CachedReader) add(e *cacheEntry) {
+
+	size := e.size()
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := c.entryMap(e)
+
+	if el, ok := entries[e.offset]; ok {
+		c.bytes -= el.Value.(*cacheEntry).size()
+		c.lru.Remove(el)
+		delete(entries, e.offset)
+	}
+
+	for c.bytes+size > c.maxBytes {
+		el := c.lru.Back()
+		old := el.Value.(*cacheEntry)
+		c.bytes -= old.size()
+		c.lru.Remove(el)
+		delete(c.entryMap(old), old.offset)
+		c.evictions++
+	}
+
+	entries[e.offset] = c.lru.PushFront(e)
+	c.bytes += size
+}
+
+// Stats returns a snapshot of the statistics of this cache.
+func (c *CachedReader) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Entries:   len(c.entries) + len(c.blocks),
+		Bytes:     c.bytes,
+	}
+}
+
+// innerReader returns the DataReader that is not a CachedReader, behind any
+// CachedReader wrapping it.
+func innerReader(dr DataReader) DataReader {
+	for {
+		c, ok := dr.(*CachedReader)
+		if !ok {
+			return dr
+		}
+		dr = c.DataReader
+	}
+}
+
+// asBlockReader returns `dr` as a BlockReader if the DataReader behind it is.
+func asBlockReader(dr DataReader) (BlockReader, bool) {
+	if _, ok := innerReader(dr).(BlockReader); !ok {
+		return nil, false
+	}
+	br, ok := dr.(BlockReader)
+	return br, ok
+}
+
+// asKeyValueReader returns `dr` as a KeyValueReader if the DataReader behind it
+// is.
+func asKeyValueReader(dr DataReader) (KeyValueReader, bool) {
+	if _, ok := innerReader(dr).(KeyValueReader); !ok {
+		return nil, false
+	}
+	kvr, ok := dr.(KeyValueReader)
+	return kvr, ok
+}
+
+// asBatchReader returns `dr` as a BatchReader if the DataReader behind it is.
+func asBatchReader(dr DataReader) (BatchReader, bool) {
+	if _, ok := innerReader(dr).(BatchReader); !ok {
+		return nil, false
+	}
+	br, ok := dr.(BatchReader)
+	return br, ok
+}
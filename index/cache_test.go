@@ -0,0 +1,326 @@
+package index_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/openacid/slim/index"
+)
+
+// countingData is a DataReader that counts how many times it is read.
+type countingData struct {
+	testIndexData
+	reads int64
+}
+
+func (d *countingData) Read(offset int64, key string) (string, bool) {
+	atomic.AddInt64(&d.reads, 1)
+	return d.testIndexData.Read(offset, key)
+}
+
+func TestCachedReader(t *testing.T) {
+
+	data := &countingData{
+		testIndexData: testIndexData("Aaron,1,Agatha,1,Al,2,Albert,3,Alexander,5,Alison,8"),
+	}
+
+	keyOffsets := []index.OffsetIndexItem{
+		{Key: "Aaron", Offset: 0},
+		{Key: "Agatha", Offset: 8},
+		{Key: "Al", Offset: 17},
+		{Key: "Albert", Offset: 22},
+		{Key: "Alexander", Offset: 31},
+		{Key: "Alison", Offset: 43},
+	}
+
+	// room for 2 records
+	cr := index.NewCachedReader(data, 2*(64+10))
+
+	st, err := index.NewSlimIndex(keyOffsets, cr)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	cases := []struct {
+		input     string
+		want      string
+		wantfound bool
+		wantStats index.CacheStats
+		wantReads int64
+	}{
+		{"Aaron", "1", true, index.CacheStats{Misses: 1, Entries: 1, Bytes: 70}, 1},
+		{"Aaron", "1", true, index.CacheStats{Hits: 1, Misses: 1, Entries: 1, Bytes: 70}, 1},
+		{"Al", "2", true, index.CacheStats{Hits: 1, Misses: 2, Entries: 2, Bytes: 137}, 2},
+		{"Aaron", "1", true, index.CacheStats{Hits: 2, Misses: 2, Entries: 2, Bytes: 137}, 2},
+		// evicts "Al"
+		{"Alison", "8", true, index.CacheStats{Hits: 2, Misses: 3, Evictions: 1, Entries: 2, Bytes: 141}, 3},
+		{"Al", "2", true, index.CacheStats{Hits: 2, Misses: 4, Evictions: 2, Entries: 2, Bytes: 138}, 4},
+	}
+
+	for i, c := range cases {
+		rst, found := st.Get2(c.input)
+		if rst != c.want || found != c.wantfound {
+			t.Fatalf("%d-th: input: %v; want: %v %v; actual: %v %v",
+				i+1, c.input, c.want, c.wantfound, rst, found)
+		}
+
+		stats := cr.Stats()
+		if stats != c.wantStats {
+			t.Fatalf("%d-th: input: %v; wantStats: %+v; actual: %+v",
+				i+1, c.input, c.wantStats, stats)
+		}
+
+		if data.reads != c.wantReads {
+			t.Fatalf("%d-th: input: %v; wantReads: %d; actual: %d",
+				i+1, c.input, c.wantReads, data.reads)
+		}
+	}
+
+	// not found is not cached
+	for i := 0; i < 2; i++ {
+		_, found := cr.Read(22, "foo")
+		if found {
+			t.Fatalf("expect not found")
+		}
+	}
+	// a cached offset read with a different key
+	_, found := cr.Read(43, "Al")
+	if found {
+		t.Fatalf("expect not found with a different key at a cached offset")
+	}
+
+	want := index.CacheStats{Hits: 2, Misses: 7, Evictions: 2, Entries: 2, Bytes: 138}
+	if cr.Stats() != want || data.reads != 7 {
+		t.Fatalf("wantStats: %+v; actual: %+v; reads: %d", want, cr.Stats(), data.reads)
+	}
+}
+
+func TestCachedReaderConcurrent(t *testing.T) {
+
+	n := 200
+	data, items := makeTestBlockData(n)
+
+	cr := index.NewCachedReader(data, 50*(64+10))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				j := (i*(g+1) + g) % n
+				k := items[j].Key
+				v, found := cr.Read(items[j].Offset, k)
+				if !found || v != fmt.Sprintf("%d", j) {
+					t.Errorf("key: %s; want: %d; actual: %v %v", k, j, v, found)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	stats := cr.Stats()
+	if stats.Hits+stats.Misses != 8*1000 {
+		t.Fatalf("expect 8000 reads but: %+v", stats)
+	}
+	if stats.Bytes > 50*(64+10) {
+		t.Fatalf("cache exceeds max bytes: %+v", stats)
+	}
+}
+
+// countingBlockData is a BlockReader that counts how many blocks are read.
+type countingBlockData struct {
+	testBlockData
+	blockReads int64
+}
+
+func (d *countingBlockData) ReadBlock(offset int64, n int) ([]string, []string) {
+	atomic.AddInt64(&d.blockReads, 1)
+	return d.testBlockData.ReadBlock(offset, n)
+}
+
+func TestCachedReaderBlock(t *testing.T) {
+
+	n := 100
+	blockSize := 4
+	nBlocks := n / blockSize
+
+	blockData, items := makeTestBlockData(n)
+	data := &countingBlockData{testBlockData: blockData}
+
+	cr := index.NewCachedReader(data, 1024*1024)
+
+	st, err := index.NewSlimIndex(items, cr, index.BlockSize(blockSize))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for round := 0; round < 3; round++ {
+		for i, it := range items {
+			rst, found := st.Get2(it.Key)
+			if !found || rst != fmt.Sprintf("%d", i) {
+				t.Fatalf("input: %v; want: %d; actual: %v %v", it.Key, i, rst, found)
+			}
+		}
+	}
+
+	if data.blockReads != int64(nBlocks) {
+		t.Fatalf("expect every block to be read once: %d but: %d", nBlocks, data.blockReads)
+	}
+
+	stats := cr.Stats()
+	if stats.Entries != nBlocks || stats.Misses != int64(nBlocks) || stats.Evictions != 0 {
+		t.Fatalf("expect %d cached blocks but: %+v", nBlocks, stats)
+	}
+
+	// room for 2 blocks
+	data.blockReads = 0
+	cr = index.NewCachedReader(data, 2*(64+4*10))
+	for i := 0; i < 3*blockSize; i++ {
+		for _, b := range []int{0, 1} {
+			j := b*blockSize + i%blockSize
+			keys, _ := cr.ReadBlock(items[b*blockSize].Offset, blockSize)
+			if keys[i%blockSize] != items[j].Key {
+				t.Fatalf("expect key: %s but: %v", items[j].Key, keys)
+			}
+		}
+	}
+	if data.blockReads != 2 {
+		t.Fatalf("expect 2 block reads but: %d", data.blockReads)
+	}
+	keys, _ := cr.ReadBlock(items[2*blockSize].Offset, blockSize)
+	if len(keys) != blockSize || cr.Stats().Evictions != 1 || cr.Stats().Entries != 2 {
+		t.Fatalf("expect to evict a block but: %v %+v", keys, cr.Stats())
+	}
+
+	// not a BlockReader
+	cr = index.NewCachedReader(testIndexData("a,1,"), 1024)
+	if keys, _ := cr.ReadBlock(0, 1); len(keys) != 0 {
+		t.Fatalf("expect no block from a DataReader but: %v", keys)
+	}
+}
+
+func TestCachedReaderNotBlockReader(t *testing.T) {
+
+	_, items := makeTestBlockData(16)
+
+	// the wrapped DataReader is not a BlockReader
+	cr := index.NewCachedReader(testIndexData("a,1,"), 1024)
+	_, err := index.NewSlimIndex(items, cr, index.BlockSize(4))
+	if err != index.ErrNotBlockReader {
+		t.Fatalf("expect ErrNotBlockReader but: %v", err)
+	}
+
+	// nor through another CachedReader
+	_, err = index.NewSlimIndex(items, index.NewCachedReader(cr, 1024), index.BlockSize(4))
+	if err != index.ErrNotBlockReader {
+		t.Fatalf("expect ErrNotBlockReader but: %v", err)
+	}
+}
+
+func TestCachedReaderKeyValue(t *testing.T) {
+
+	keys := []string{"aa", "cR", "ca", "caa", "cb", "cbb"}
+
+	vdata := testVersionedData{}
+	items := []index.OffsetIndexItem{}
+	for _, k := range keys {
+		for _, ts := range []uint64{5, 1} {
+			items = append(items, index.OffsetIndexItem{Key: index.VersionKey(k, ts), Offset: int64(len(vdata))})
+			vdata = append(vdata, [2]string{index.VersionKey(k, ts), fmt.Sprintf("%s-%d", k, ts)})
+		}
+	}
+
+	data := &countingVersionedData{testVersionedData: vdata}
+	cr := index.NewCachedReader(data, 1024*1024)
+
+	st, err := index.NewSlimIndex(items, cr)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for round := 0; round < 2; round++ {
+
+		data.reads = 0
+
+		for _, k := range keys {
+			rst, found := st.GetAsOf(k, 3)
+			if !found || rst != k+"-1" {
+				t.Fatalf("input: %q; want: %s; actual: %v %v", k, k+"-1", rst, found)
+			}
+		}
+
+		offset, found := st.Seek(index.VersionKey("cS", 0))
+		if !found || offset != 4 {
+			t.Fatalf("expect offset 4 but: %v %v", offset, found)
+		}
+
+		// every record is served by cache in the second round
+		if round == 1 && data.reads != 0 {
+			t.Fatalf("expect no reads but: %d", data.reads)
+		}
+	}
+
+	// not a KeyValueReader
+
+	st, err = index.NewSlimIndex(items, index.NewCachedReader(testIndexData(""), 1024))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if _, found := st.Seek("a"); found {
+		t.Fatalf("expect not found without KeyValueReader")
+	}
+}
+
+func TestCachedReaderGetBatch(t *testing.T) {
+
+	n := 100
+	blockData, items := makeTestBlockData(n)
+	data := &testBatchData{testBlockData: blockData}
+
+	st, err := index.NewSlimIndex(items, index.NewCachedReader(data, 1024))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	keys := []string{"foo"}
+	for _, it := range items {
+		keys = append(keys, it.Key)
+	}
+
+	vals, found, err := st.GetBatch(keys)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if found[0] {
+		t.Fatalf("expect foo not found but: %v", vals[0])
+	}
+	for i := 1; i < len(keys); i++ {
+		if !found[i] || vals[i] != fmt.Sprintf("%d", i-1) {
+			t.Fatalf("input: %v; want: %d; actual: %v %v", keys[i], i-1, vals[i], found[i])
+		}
+	}
+
+	// records are read with a few ReadAt through the cache
+	if data.readAts == 0 || data.readAts > int64(n/10) {
+		t.Fatalf("expect reads to be coalesced but: %d ReadAt", data.readAts)
+	}
+
+	// not a BatchReader
+
+	cr := index.NewCachedReader(testIndexData("a,1,"), 1024)
+	if _, err := cr.ReadAt(make([]byte, 1), 0); err != index.ErrNotBatchReader {
+		t.Fatalf("expect ErrNotBatchReader but: %v", err)
+	}
+	st, err = index.NewSlimIndex([]index.OffsetIndexItem{{Key: "a", Offset: 0}}, cr)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	vals, found, err = st.GetBatch([]string{"a", "b"})
+	if err != nil || !found[0] || vals[0] != "1" || found[1] {
+		t.Fatalf("expect a found but: %v %v %v", vals, found, err)
+	}
+}
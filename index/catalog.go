@@ -0,0 +1,242 @@
+package index
+
+import (
+	"errors"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/openacid/slim/marshal"
+)
+
+var (
+	// ErrUnknownIndex indicates a Catalog does not have an index of the
+	// specified name.
+	ErrUnknownIndex = errors.New("unknown index name")
+	// ErrIndexNamesNotMatch indicates the names of serialized indexes are
+	// different from names of the Catalog to load them.
+	ErrIndexNamesNotMatch = errors.New("index names not match")
+	// ErrIndexNameTooLong indicates an index name is longer than 65535 bytes
+	// and can not be serialized.
+	ErrIndexNameTooLong = errors.New("index name is longer than 65535 bytes")
+)
+
+// KeyFunc extracts an index key from a record.
+type KeyFunc func(record string) string
+
+// RecordReader reads a complete record from a data file.
+type RecordReader interface {
+	// ReadRecord returns the record at `offset` and a bool indicating if there
+	// is a record.
+	ReadRecord(offset int64) (string, bool)
+}
+
+// RecordScanner reads records one by one from a data file.
+type RecordScanner interface {
+	// Next returns the offset of the next record and the record.
+	// It returns io.EOF if there is no more record.
+	Next() (int64, string, error)
+}
+
+// keyedReader implements DataReader with a RecordReader.
+// It checks the key of a record with a KeyFunc.
+type keyedReader struct {
+	RecordReader
+	keyOf KeyFunc
+}
+
+func (r keyedReader) Read(offset int64, key string) (string, bool) {
+	record, found := r.ReadRecord(offset)
+	if !found || r.keyOf(record) != key {
+		return "", false
+	}
+	return record, true
+}
+
+// Catalog is a set of named SlimIndexes over one data file, such as an index by
+// id and another index by email.
+// All of the indexes share one RecordReader.
+//
+// A key in a Catalog index must identify only one record.
+type Catalog struct {
+	RecordReader
+
+	// Indexes are SlimIndexes by name.
+	Indexes map[string]*SlimIndex
+
+	keyFuncs map[string]KeyFunc
+	opts     map[string][]Option
+}
+
+// NewCatalog creates an empty Catalog with an index for every KeyFunc in
+// `keyFuncs`, by the same name.
+// `opts` are options of indexes by name, such as Weighted with weights in the
+// key order of one index.
+// `opts` can be nil, and options of a name not in `keyFuncs` are ignored.
+//
+// Records are not sorted by keys of all indexes.
+// Thus block mode of SlimIndex is not supported, and Build returns
+// ErrNotBlockReader with option BlockSize greater than 1.
+//
+// Use Build to create indexes from a data file, or Unmarshal to load
+// serialized indexes.
+func NewCatalog(rr RecordReader, keyFuncs map[string]KeyFunc, opts map[string][]Option) *Catalog {
+	return &Catalog{
+		RecordReader: rr,
+		Indexes:      make(map[string]*SlimIndex),
+		keyFuncs:     keyFuncs,
+		opts:         opts,
+	}
+}
+
+// Build creates all indexes from one pass over records in `scanner`.
+func (c *Catalog) Build(scanner RecordScanner) error {
+
+	items := make(map[string][]OffsetIndexItem, len(c.keyFuncs))
+
+	for {
+		offset, record, err := scanner.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		for name, keyOf := range c.keyFuncs {
+			items[name] = append(items[name], OffsetIndexItem{
+				Key:    keyOf(record),
+				Offset: offset,
+			})
+		}
+	}
+
+	for name := range c.keyFuncs {
+		its := items[name]
+		sort.SliceStable(its, func(i, j int) bool {
+			return its[i].Key < its[j].Key
+		})
+
+		si, err := c.newIndex(name, its, c.opts[name]...)
+		if err != nil {
+			return err
+		}
+		c.Indexes[name] = si
+	}
+
+	return nil
+}
+
+func (c *Catalog) newIndex(name string, items []OffsetIndexItem, opts ...Option) (*SlimIndex, error) {
+	dr := keyedReader{c.RecordReader, c.keyFuncs[name]}
+	return NewSlimIndex(items, dr, opts...)
+}
+
+// Get2 returns the record of `key` in index `name`, and a bool value
+// indicating if the `key` is found or not.
+func (c *Catalog) Get2(name, key string) (string, bool) {
+	si, ok := c.Indexes[name]
+	if !ok {
+		return "", false
+	}
+	return si.Get2(key)
+}
+
+// names returns sorted index names.
+func (c *Catalog) names() []string {
+	names := make([]string, 0, len(c.keyFuncs))
+	for name := range c.keyFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Marshal serializes all indexes to byte stream.
+//
+// It writes the number of indexes, then the name and the serialized index, for
+// every index in the order of names.
+// The number of indexes is a little-endian uint32, and a name is a
+// little-endian uint16 length followed by the name.
+// It returns ErrIndexNameTooLong if a name is longer than 65535 bytes.
+func (c *Catalog) Marshal(writer io.Writer) (cnt int64, err error) {
+
+	names := c.names()
+
+	b := marshal.U32{}.Marshal(uint32(len(names)))
+	if _, err = writer.Write(b); err != nil {
+		return 0, err
+	}
+	cnt += int64(len(b))
+
+	for _, name := range names {
+		si, ok := c.Indexes[name]
+		if !ok {
+			return 0, ErrUnknownIndex
+		}
+
+		if len(name) > math.MaxUint16 {
+			return 0, ErrIndexNameTooLong
+		}
+
+		b := append(marshal.U16{}.Marshal(uint16(len(name))), name...)
+		if _, err = writer.Write(b); err != nil {
+			return 0, err
+		}
+		cnt += int64(len(b))
+
+		n, err := si.Marshal(writer)
+		if err != nil {
+			return 0, err
+		}
+		cnt += n
+	}
+
+	return cnt, nil
+}
+
+// Unmarshal de-serializes and loads all indexes from a byte stream.
+//
+// The Catalog must be created with the same index names as the serialized one.
+// Options of every index are loaded from the byte stream.
+func (c *Catalog) Unmarshal(reader io.Reader) error {
+
+	names := c.names()
+
+	buf := make([]byte, marshal.U32{}.GetMarshaledSize(nil))
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return err
+	}
+	_, n := marshal.U32{}.Unmarshal(buf)
+	if int(n.(uint32)) != len(names) {
+		return ErrIndexNamesNotMatch
+	}
+
+	for _, name := range names {
+
+		buf := make([]byte, marshal.U16{}.GetMarshaledSize(nil))
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return err
+		}
+		_, l := marshal.U16{}.Unmarshal(buf)
+
+		buf = make([]byte, l.(uint16))
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return err
+		}
+		if string(buf) != name {
+			return ErrIndexNamesNotMatch
+		}
+
+		si, err := c.newIndex(name, nil)
+		if err != nil {
+			return err
+		}
+		if err := si.Unmarshal(reader); err != nil {
+			return err
+		}
+		c.Indexes[name] = si
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package index_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/index"
+)
+
+// testLines is a data file of records separated by "\n".
+type testLines string
+
+func (d testLines) ReadRecord(offset int64) (string, bool) {
+	if offset < 0 || offset >= int64(len(d)) {
+		return "", false
+	}
+	return strings.SplitN(string(d)[offset:], "\n", 2)[0], true
+}
+
+type testLineScanner struct {
+	data   testLines
+	offset int64
+}
+
+func (s *testLineScanner) Next() (int64, string, error) {
+	offset := s.offset
+	record, found := s.data.ReadRecord(offset)
+	if !found {
+		return 0, "", io.EOF
+	}
+	s.offset += int64(len(record)) + 1
+	return offset, record, nil
+}
+
+func testField(i int) index.KeyFunc {
+	return func(record string) string {
+		return strings.Split(record, ",")[i]
+	}
+}
+
+func TestCatalog(t *testing.T) {
+
+	data := testLines("" +
+		"3,cc@x.com,Carol\n" +
+		"1,aa@x.com,Alice\n" +
+		"4,dd@x.com,Dave\n" +
+		"2,bb@x.com,Bob\n")
+
+	keyFuncs := map[string]index.KeyFunc{
+		"id":    testField(0),
+		"email": testField(1),
+	}
+
+	for _, opts := range []map[string][]index.Option{
+		nil,
+		{"id": {index.CompressOffsets()}},
+		{"id": {index.CompressOffsets()}, "email": {index.CompressOffsets()}},
+		{"email": {index.Weighted([]uint32{1, 2, 3, 4})}, "name": {index.BlockSize(0)}},
+	} {
+
+		c := index.NewCatalog(data, keyFuncs, opts)
+		err := c.Build(&testLineScanner{data: data})
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		buf := new(bytes.Buffer)
+		cnt, err := c.Marshal(buf)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		if cnt != int64(buf.Len()) {
+			t.Fatalf("expect %d bytes written but: %d", buf.Len(), cnt)
+		}
+
+		// little-endian count of indexes, then the length of the first name.
+		if want := "\x02\x00\x00\x00\x05\x00email"; !strings.HasPrefix(buf.String(), want) {
+			t.Fatalf("expect to start with %q but: %q", want, buf.String()[:len(want)])
+		}
+
+		loaded := index.NewCatalog(data, keyFuncs, opts)
+		err = loaded.Unmarshal(buf)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		cases := []struct {
+			name      string
+			key       string
+			want      string
+			wantfound bool
+		}{
+			{"id", "1", "1,aa@x.com,Alice", true},
+			{"id", "2", "2,bb@x.com,Bob", true},
+			{"id", "3", "3,cc@x.com,Carol", true},
+			{"id", "4", "4,dd@x.com,Dave", true},
+			{"id", "5", "", false},
+			{"id", "aa@x.com", "", false},
+			{"email", "aa@x.com", "1,aa@x.com,Alice", true},
+			{"email", "bb@x.com", "2,bb@x.com,Bob", true},
+			{"email", "cc@x.com", "3,cc@x.com,Carol", true},
+			{"email", "dd@x.com", "4,dd@x.com,Dave", true},
+			{"email", "ee@x.com", "", false},
+			{"email", "1", "", false},
+			{"name", "Alice", "", false},
+		}
+
+		for _, cat := range []*index.Catalog{c, loaded} {
+			for i, cc := range cases {
+				rst, found := cat.Get2(cc.name, cc.key)
+				if rst != cc.want || found != cc.wantfound {
+					t.Fatalf("%d-th: input: %v %v; want: %v %v; actual: %v %v",
+						i+1, cc.name, cc.key, cc.want, cc.wantfound, rst, found)
+				}
+			}
+		}
+	}
+
+	// load with different names
+
+	c := index.NewCatalog(data, keyFuncs, nil)
+	if err := c.Build(&testLineScanner{data: data}); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := c.Marshal(buf); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded := index.NewCatalog(data, map[string]index.KeyFunc{
+		"id":   testField(0),
+		"name": testField(2),
+	}, nil)
+	err := loaded.Unmarshal(buf)
+	if err != index.ErrIndexNamesNotMatch {
+		t.Fatalf("expect ErrIndexNamesNotMatch but: %v", err)
+	}
+
+	// options are per index
+
+	c = index.NewCatalog(data, keyFuncs, map[string][]index.Option{
+		"email": {index.Weighted([]uint32{1})},
+	})
+	if err := c.Build(&testLineScanner{data: data}); err != index.ErrWeightsLen {
+		t.Fatalf("expect ErrWeightsLen but: %v", err)
+	}
+
+	// too long name
+
+	long := strings.Repeat("x", 65536)
+	c = index.NewCatalog(data, map[string]index.KeyFunc{long: testField(0)}, nil)
+	if err := c.Build(&testLineScanner{data: data}); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if _, err := c.Marshal(new(bytes.Buffer)); err != index.ErrIndexNameTooLong {
+		t.Fatalf("expect ErrIndexNameTooLong but: %v", err)
+	}
+}
+
+func TestCatalogBlockMode(t *testing.T) {
+
+	data := testLines("1,aa@x.com,Alice\n2,bb@x.com,Bob\n")
+	keyFuncs := map[string]index.KeyFunc{"id": testField(0)}
+
+	for _, c := range []struct {
+		n       int
+		wanterr error
+	}{
+		{0, index.ErrBlockSize},
+		{-1, index.ErrBlockSize},
+		{2, index.ErrNotBlockReader},
+	} {
+		cat := index.NewCatalog(data, keyFuncs, map[string][]index.Option{
+			"id": {index.BlockSize(c.n)},
+		})
+		err := cat.Build(&testLineScanner{data: data})
+		if err != c.wanterr {
+			t.Fatalf("BlockSize(%d): expect error: %v but: %v", c.n, c.wanterr, err)
+		}
+	}
+}
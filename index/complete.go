@@ -0,0 +1,190 @@
+package index
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+)
+
+// Weighted makes a SlimIndex store the weight of every record and the max
+// weight of every sub-trie, for `Complete`.
+//
+// `weights[i]` is the weight of the i-th item passed to NewSlimIndex, thus
+// there must be as many weights as index items.
+// When loading a SlimIndex with Unmarshal, `weights` is not used and could be
+// nil.
+//
+// It takes 4 bytes per key and 4 bytes per inner node of SlimTrie.
+// Block mode does not support it.
+func Weighted(weights []uint32) Option {
+	return func(si *SlimIndex) {
+		si.weighted = true
+		si.weights = weights
+	}
+}
+
+// initWeights builds weight annotations from `weights` of every indexed key,
+// in key order.
+func (si *SlimIndex) initWeights(weights []uint32) error {
+
+	if !si.weighted || si.blockSize > 1 || len(weights) == 0 {
+		return nil
+	}
+
+	// Leaves visited by a depth-first traversal in branch order are in key
+	// order: the leaf value of a node comes before all of its children.
+	var maxIdx, leafIdx []uint32
+	var maxElts, leafElts []uint32
+
+	i := 0
+	var walk func(idx int32) uint32
+	walk = func(idx int32) uint32 {
+		w := uint32(0)
+		if si.Leaves.Has(uint32(idx)) {
+			w = weights[i]
+			i++
+			leafIdx = append(leafIdx, uint32(idx))
+			leafElts = append(leafElts, w)
+		}
+
+		children := si.ChildNodes(idx)
+		for _, c := range children {
+			cw := walk(c)
+			if cw > w {
+				w = cw
+			}
+		}
+		if len(children) > 0 {
+			maxIdx = append(maxIdx, uint32(idx))
+			maxElts = append(maxElts, w)
+		}
+		return w
+	}
+	walk(0)
+
+	if i != len(weights) {
+		return ErrKeyNotIndexed
+	}
+
+	sortByIndex(maxIdx, maxElts)
+	sortByIndex(leafIdx, leafElts)
+
+	if err := si.maxWeights.Init(maxIdx, maxElts); err != nil {
+		return err
+	}
+	return si.leafWeights.Init(leafIdx, leafElts)
+}
+
+// sortByIndex sorts `indexes` in ascending order, and moves `elts` along with
+// them.
+func sortByIndex(indexes []uint32, elts []uint32) {
+	sort.Sort(byIndex{indexes, elts})
+}
+
+type byIndex struct {
+	indexes []uint32
+	elts    []uint32
+}
+
+func (s byIndex) Len() int           { return len(s.indexes) }
+func (s byIndex) Less(i, j int) bool { return s.indexes[i] < s.indexes[j] }
+func (s byIndex) Swap(i, j int) {
+	s.indexes[i], s.indexes[j] = s.indexes[j], s.indexes[i]
+	s.elts[i], s.elts[j] = s.elts[j], s.elts[i]
+}
+
+// completeCandidate is a leaf or a sub-trie to visit in Complete.
+type completeCandidate struct {
+	// weight is the weight of a leaf, or the max weight in a sub-trie.
+	weight uint32
+	node   int32
+	leaf   bool
+}
+
+// completeHeap is a max-heap of candidates by weight.
+type completeHeap []completeCandidate
+
+func (h completeHeap) Len() int            { return len(h) }
+func (h completeHeap) Less(i, j int) bool  { return h[i].weight > h[j].weight }
+func (h completeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *completeHeap) Push(x interface{}) { *h = append(*h, x.(completeCandidate)) }
+func (h *completeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// Complete returns at most `k` keys starting with `prefix`, with the greatest
+// weights, in descending order of weight.
+// Keys of the same weight are in no particular order.
+//
+// The SlimIndex must be created with the Weighted option, and
+// `SlimIndex.DataReader` must be a KeyValueReader, which returns the index
+// keys.
+// Otherwise it returns nil.
+//
+// It visits sub-tries under `prefix` in descending order of their max weight,
+// thus it stops without visiting every leaf once it has found `k` keys.
+// SlimTrie does not store complete keys, thus the key of every candidate record
+// is read and confirmed with the DataReader.
+// All keys under the prefix node share the words up to the length of `prefix`,
+// thus if one of them does not start with `prefix`, none does and it stops.
+func (si *SlimIndex) Complete(prefix string, k int) []string {
+
+	kvr, ok := asKeyValueReader(si.DataReader)
+	if !ok || !si.weighted || si.blockSize > 1 || k <= 0 {
+		return nil
+	}
+
+	root := si.PrefixNode(prefix)
+	if root == -1 {
+		return nil
+	}
+
+	h := &completeHeap{}
+	si.pushCandidates(h, root)
+
+	rst := []string{}
+	for h.Len() > 0 && len(rst) < k {
+		c := heap.Pop(h).(completeCandidate)
+
+		if !c.leaf {
+			for _, child := range si.ChildNodes(c.node) {
+				si.pushCandidates(h, child)
+			}
+			continue
+		}
+
+		key, _, found := kvr.ReadKeyValue(si.leafOffset(c.node))
+		if !found {
+			continue
+		}
+		if !strings.HasPrefix(key, prefix) {
+			return rst
+		}
+		rst = append(rst, key)
+	}
+
+	return rst
+}
+
+// pushCandidates pushes the leaf value and the children of node `idx`.
+func (si *SlimIndex) pushCandidates(h *completeHeap, idx int32) {
+	if w, found := si.leafWeights.Get2(uint32(idx)); found {
+		heap.Push(h, completeCandidate{weight: w.(uint32), node: idx, leaf: true})
+	}
+	if w, found := si.maxWeights.Get2(uint32(idx)); found {
+		heap.Push(h, completeCandidate{weight: w.(uint32), node: idx})
+	}
+}
+
+// leafOffset returns the offset stored for leaf node `idx`.
+func (si *SlimIndex) leafOffset(idx int32) int64 {
+	if si.offsets != nil {
+		ord, _ := si.Leaves.GetEltIndex(uint32(idx))
+		return si.offsets.get(ord)
+	}
+	return si.Leaves.Get(uint32(idx)).(int64)
+}
@@ -0,0 +1,204 @@
+package index_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/index"
+)
+
+func TestSlimIndexComplete(t *testing.T) {
+
+	terms := []struct {
+		key    string
+		weight uint32
+	}{
+		{"app", 50},
+		{"apple", 90},
+		{"applet", 10},
+		{"application", 70},
+		{"apply", 60},
+		{"apt", 20},
+		{"banana", 80},
+		{"band", 30},
+		{"bandana", 40},
+		{"can", 5},
+	}
+
+	data := testVersionedData{}
+	items := []index.OffsetIndexItem{}
+	weights := []uint32{}
+	for i, tm := range terms {
+		data = append(data, [2]string{tm.key, ""})
+		items = append(items, index.OffsetIndexItem{Key: tm.key, Offset: int64(i)})
+		weights = append(weights, tm.weight)
+	}
+
+	cases := []struct {
+		prefix string
+		k      int
+		want   []string
+	}{
+		{"app", 3, []string{"apple", "application", "apply"}},
+		{"app", 10, []string{"apple", "application", "apply", "app", "applet"}},
+		{"ap", 1, []string{"apple"}},
+		{"appl", 2, []string{"apple", "application"}},
+		{"applet", 5, []string{"applet"}},
+		{"ban", 5, []string{"banana", "bandana", "band"}},
+		{"", 4, []string{"apple", "banana", "application", "apply"}},
+		{"c", 5, []string{"can"}},
+		{"cat", 5, []string{}},
+		{"apples", 5, []string{}},
+		{"x", 5, nil},
+		{"app", 0, nil},
+	}
+
+	for _, opts := range [][]index.Option{
+		{index.Weighted(weights)},
+		{index.Weighted(weights), index.CompressOffsets()},
+	} {
+
+		st, err := index.NewSlimIndex(items, data, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		buf := new(bytes.Buffer)
+		if _, err := st.Marshal(buf); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		loaded, err := index.NewSlimIndex(nil, data)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		if err := loaded.Unmarshal(buf); err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for _, si := range []*index.SlimIndex{st, loaded} {
+			for i, c := range cases {
+				rst := si.Complete(c.prefix, c.k)
+				if !reflect.DeepEqual(c.want, rst) {
+					t.Fatalf("%d-th: input: %q %d; want: %v; actual: %v",
+						i+1, c.prefix, c.k, c.want, rst)
+				}
+			}
+		}
+	}
+
+	// without Weighted
+
+	st, err := index.NewSlimIndex(items, data)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if rst := st.Complete("app", 3); rst != nil {
+		t.Fatalf("expect nil without Weighted but: %v", rst)
+	}
+}
+
+func TestSlimIndexCompleteMany(t *testing.T) {
+
+	n := 1000
+
+	data := testVersionedData{}
+	items := []index.OffsetIndexItem{}
+	weights := []uint32{}
+	for i := 0; i < n; i++ {
+		k := fmt.Sprintf("k%03x", i)
+		data = append(data, [2]string{k, ""})
+		items = append(items, index.OffsetIndexItem{Key: k, Offset: int64(i)})
+		weights = append(weights, uint32((i*7919)%n))
+	}
+
+	st, err := index.NewSlimIndex(items, data, index.Weighted(weights))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for _, prefix := range []string{"", "k", "k1", "k2f", "k3e7"} {
+
+		// brute force
+		matched := []int{}
+		for i, it := range items {
+			if strings.HasPrefix(it.Key, prefix) {
+				matched = append(matched, i)
+			}
+		}
+		sort.Slice(matched, func(i, j int) bool {
+			return weights[matched[i]] > weights[matched[j]]
+		})
+
+		want := []string{}
+		for i := 0; i < len(matched) && i < 10; i++ {
+			want = append(want, items[matched[i]].Key)
+		}
+
+		rst := st.Complete(prefix, 10)
+		if !reflect.DeepEqual(want, rst) {
+			t.Fatalf("prefix: %q; want: %v; actual: %v", prefix, want, rst)
+		}
+	}
+}
+
+func TestSlimIndexCompleteAbsentReads(t *testing.T) {
+
+	// All keys share "abc", which SlimTrie skips, thus an absent prefix such
+	// as "az" is routed into the sub-trie of all keys.
+	n := 5000
+
+	data := &countingVersionedData{}
+	items := []index.OffsetIndexItem{}
+	weights := []uint32{}
+	for i := 0; i < n; i++ {
+		k := fmt.Sprintf("abc%05d", i)
+		data.testVersionedData = append(data.testVersionedData, [2]string{k, ""})
+		items = append(items, index.OffsetIndexItem{Key: k, Offset: int64(i)})
+		weights = append(weights, uint32(i))
+	}
+
+	st, err := index.NewSlimIndex(items, data, index.Weighted(weights))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for _, prefix := range []string{"az", "abd", "abc1x", "abc049999"} {
+		data.reads = 0
+		rst := st.Complete(prefix, 5)
+		if len(rst) != 0 {
+			t.Fatalf("prefix: %q; expect no key but: %v", prefix, rst)
+		}
+		if data.reads > 1 {
+			t.Fatalf("prefix: %q; expect at most 1 read but: %d", prefix, data.reads)
+		}
+	}
+
+	data.reads = 0
+	rst := st.Complete("abc0", 5)
+	want := []string{"abc04999", "abc04998", "abc04997", "abc04996", "abc04995"}
+	if !reflect.DeepEqual(want, rst) {
+		t.Fatalf("want: %v; actual: %v", want, rst)
+	}
+	if data.reads != 5 {
+		t.Fatalf("expect 5 reads but: %d", data.reads)
+	}
+}
+
+func TestSlimIndexWeightsLen(t *testing.T) {
+
+	items := []index.OffsetIndexItem{
+		{Key: "a", Offset: 0},
+		{Key: "b", Offset: 1},
+	}
+
+	for _, weights := range [][]uint32{nil, {1}, {1, 2, 3}} {
+		_, err := index.NewSlimIndex(items, testVersionedData{}, index.Weighted(weights))
+		if err != index.ErrWeightsLen {
+			t.Fatalf("weights: %v; expect ErrWeightsLen but: %v", weights, err)
+		}
+	}
+}
@@ -12,10 +12,32 @@
 package index
 
 import (
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/openacid/slim/array"
 	"github.com/openacid/slim/marshal"
+	"github.com/openacid/slim/prototype"
+	"github.com/openacid/slim/serialize"
 	"github.com/openacid/slim/trie"
 )
 
+// ErrKeyNotIndexed indicates a key is not found in the SlimTrie just built from
+// it. E.g., it happens if there are too many keys for a SlimTrie.
+var ErrKeyNotIndexed = errors.New("key is not indexed")
+
+// ErrBlockSize indicates the block size of a SlimIndex is less than 1.
+var ErrBlockSize = errors.New("block size must be greater than 0")
+
+// ErrNotBlockReader indicates a SlimIndex in block mode is created with a
+// DataReader that is not a BlockReader.
+var ErrNotBlockReader = errors.New("block mode requires a BlockReader")
+
+// ErrWeightsLen indicates the weights passed to the Weighted option are not as
+// many as the index items.
+var ErrWeightsLen = errors.New("weights must be as many as index items")
+
 // DataReader defines interface to let SlimIndex access the data it indexes.
 type DataReader interface {
 	// Read value at `offset`, of `key`.
@@ -28,6 +50,17 @@ type DataReader interface {
 	Read(offset int64, key string) (string, bool)
 }
 
+// BlockReader is a DataReader that reads a block of records.
+// Block mode of SlimIndex requires it.
+type BlockReader interface {
+	DataReader
+
+	// ReadBlock returns keys and values of at most `n` records starting from
+	// the record at `offset`, in key order.
+	// It returns nothing if there is no record at `offset`.
+	ReadBlock(offset int64, n int) (keys, values []string)
+}
+
 // OffsetIndexItem defines data types for a offset-based index, such as an index
 // of on-disk records.
 type OffsetIndexItem struct {
@@ -43,38 +76,422 @@ type OffsetIndexItem struct {
 type SlimIndex struct {
 	trie.SlimTrie
 	DataReader
+
+	// blockSize is the number of records per block in block mode.
+	// 1 means every record is indexed.
+	blockSize int
+
+	// compressOffsets indicates to store offsets in `offsets` instead of in
+	// SlimTrie.Leaves.
+	compressOffsets bool
+	offsets         *packedOffsets
+
+	// batchParallel is the max number of concurrent reads GetBatch issues.
+	batchParallel int
+
+	// weighted indicates to store weights of records for Complete.
+	weighted bool
+	// weights are weights of index items passed to the Weighted option.
+	// It is used only when creating a SlimIndex.
+	weights []uint32
+	// maxWeights stores the max weight in the sub-trie of every inner node,
+	// by node id.
+	maxWeights array.Array32
+	// leafWeights stores the weight of every leaf node, by node id.
+	leafWeights array.Array32
+}
+
+// Option configures a SlimIndex when creating it with NewSlimIndex.
+type Option func(*SlimIndex)
+
+// BlockSize makes a SlimIndex index only the first record of every `n`
+// records, as a sparse index.
+//
+// Memory consumption drops by about `n` times.
+// In exchange, `Get2` routes a key to the block whose first key is the greatest
+// one <= key, and reads the block with `BlockReader.ReadBlock`.
+// Thus the DataReader must be a BlockReader.
+//
+// For records of about 100 bytes, n=40 makes one indexed key per 4 KB block.
+// `n` must be greater than 0, and 1 means every record is indexed.
+func BlockSize(n int) Option {
+	return func(si *SlimIndex) {
+		si.blockSize = n
+	}
+}
+
+// CompressOffsets makes a SlimIndex store offsets out of SlimTrie, in blocks
+// with frame-of-reference encoding, instead of a full 8-byte int64 per key in
+// SlimTrie.Leaves.
+//
+// Offsets are stored in the order of SlimTrie leaves, which is not key order.
+// Thus an offset takes about log2 of the data file size in bits,
+// whether or not records are sorted in the file.
+//
+// With it, `SlimIndex.SlimTrie.Get()` does not return offset any more.
+func CompressOffsets() Option {
+	return func(si *SlimIndex) {
+		si.compressOffsets = true
+	}
 }
 
 // NewSlimIndex creates SlimIndex instance.
 //
 // The keys in `index` must be in ascending order.
-func NewSlimIndex(index []OffsetIndexItem, dr DataReader) (*SlimIndex, error) {
+func NewSlimIndex(index []OffsetIndexItem, dr DataReader, opts ...Option) (*SlimIndex, error) {
+
+	si := &SlimIndex{DataReader: dr, blockSize: 1}
+	for _, opt := range opts {
+		opt(si)
+	}
+
+	if err := si.checkOptions(); err != nil {
+		return nil, err
+	}
+
+	if si.weighted && len(si.weights) != len(index) {
+		return nil, ErrWeightsLen
+	}
+
+	si.maxWeights.Converter = array.U32Conv{}
+	si.leafWeights.Converter = array.U32Conv{}
+
+	step := si.blockSize
 
 	l := len(index)
-	keys := make([]string, 0, l)
-	offsets := make([]int64, 0, l)
-	for i := 0; i < l; i++ {
+	keys := make([]string, 0, (l+step-1)/step)
+	offsets := make([]int64, 0, (l+step-1)/step)
+	weights := make([]uint32, 0, (l+step-1)/step)
+	for i := 0; i < l; i += step {
 		keys = append(keys, index[i].Key)
 		offsets = append(offsets, index[i].Offset)
+		if si.weighted {
+			weights = append(weights, si.weights[i])
+		}
 	}
+	// do not hold the caller's slice after building.
+	si.weights = nil
 
-	st, err := trie.NewSlimTrie(marshal.I64{}, keys, offsets)
+	if !si.compressOffsets {
+		st, err := trie.NewSlimTrie(marshal.I64{}, keys, offsets)
+		if err != nil {
+			return nil, err
+		}
+
+		si.SlimTrie = *st
+		return si, si.initWeights(weights)
+	}
+
+	st, err := trie.NewSlimTrie(emptyConv{}, keys, offsets)
 	if err != nil {
 		return nil, err
 	}
+	si.SlimTrie = *st
+
+	// Leaves in SlimTrie are not in key order.
+	// Re-arrange offsets by their leaf ordinals.
+	byOrdinal := make([]int64, len(offsets))
+	for i, k := range keys {
+		ord, found := st.GetLeafOrdinal(k)
+		if !found {
+			return nil, ErrKeyNotIndexed
+		}
+		byOrdinal[ord] = offsets[i]
+	}
+
+	si.offsets = newPackedOffsets(byOrdinal)
+
+	return si, si.initWeights(weights)
+}
+
+// checkOptions returns an error if options are invalid or the DataReader does
+// not support them.
+func (si *SlimIndex) checkOptions() error {
+
+	if si.blockSize < 1 {
+		return ErrBlockSize
+	}
+
+	if si.blockSize > 1 {
+		if _, ok := asBlockReader(si.DataReader); !ok {
+			return ErrNotBlockReader
+		}
+	}
 
-	return &SlimIndex{*st, dr}, nil
+	return nil
 }
 
 // Get2 returns the value of `key` which is found by `SlimIndex.DataReader`, and
 // a bool value indicating if the `key` is found or not.
 func (si *SlimIndex) Get2(key string) (string, bool) {
+
+	if si.blockSize > 1 {
+		return si.getFromBlock(key)
+	}
+
+	offset, found := si.getOffset(key)
+	if !found {
+		return "", false
+	}
+
+	return si.DataReader.Read(offset, key)
+}
+
+// Seek returns the offset of the record with the smallest key >= `key`, and a
+// bool indicating if there is such a record.
+// It is where a range scan starting from `key` starts.
+//
+// `SlimIndex.DataReader` must be a KeyValueReader, which returns the index
+// keys, and block mode is not supported.
+// It reads the key of one record.
+func (si *SlimIndex) Seek(key string) (int64, bool) {
+
+	kvr, ok := asKeyValueReader(si.DataReader)
+	if !ok || si.blockSize > 1 {
+		return 0, false
+	}
+
+	_, eq, gt := si.searchOffsetsExact(key, func(offset int64) (string, bool) {
+		k, _, found := kvr.ReadKeyValue(offset)
+		return k, found
+	})
+
+	if eq != -1 {
+		return eq, true
+	}
+	if gt != -1 {
+		return gt, true
+	}
+	return 0, false
+}
+
+// getOffset returns the offset of the record that possibly has `key`, and a
+// bool indicating if there is such a record.
+func (si *SlimIndex) getOffset(key string) (int64, bool) {
+
+	if si.offsets != nil {
+		ord, found := si.SlimTrie.GetLeafOrdinal(key)
+		if !found {
+			return 0, false
+		}
+		return si.offsets.get(ord), true
+	}
+
 	o := si.SlimTrie.Get(key)
 	if o == nil {
+		return 0, false
+	}
+
+	return o.(int64), true
+}
+
+// searchOffsets returns offsets of the records that possibly have the greatest
+// key < `key`, `key` and the smallest key > `key`.
+// An offset is -1 if there is no such a record.
+func (si *SlimIndex) searchOffsets(key string) (ltOffset, eqOffset, gtOffset int64) {
+	ltOffset, eqOffset, gtOffset = -1, -1, -1
+
+	if si.offsets != nil {
+		lt, eq, gt := si.SlimTrie.SearchLeafOrdinal(key)
+		if lt != -1 {
+			ltOffset = si.offsets.get(uint32(lt))
+		}
+		if eq != -1 {
+			eqOffset = si.offsets.get(uint32(eq))
+		}
+		if gt != -1 {
+			gtOffset = si.offsets.get(uint32(gt))
+		}
+		return
+	}
+
+	lt, eq, gt := si.SlimTrie.Search(key)
+	if lt != nil {
+		ltOffset = lt.(int64)
+	}
+	if eq != nil {
+		eqOffset = eq.(int64)
+	}
+	if gt != nil {
+		gtOffset = gt.(int64)
+	}
+	return
+}
+
+// searchOffsetsExact is similar to searchOffsets except that the returned
+// offsets are exact even if `key` is not present.
+// `keyOf` returns the key of the record at an offset, and is called once.
+func (si *SlimIndex) searchOffsetsExact(key string, keyOf func(offset int64) (string, bool)) (ltOffset, eqOffset, gtOffset int64) {
+	ltOffset, eqOffset, gtOffset = -1, -1, -1
+
+	if si.offsets != nil {
+		lt, eq, gt := si.SlimTrie.SearchLeafOrdinalExact(key, func(ord int32) (string, bool) {
+			return keyOf(si.offsets.get(uint32(ord)))
+		})
+		if lt != -1 {
+			ltOffset = si.offsets.get(uint32(lt))
+		}
+		if eq != -1 {
+			eqOffset = si.offsets.get(uint32(eq))
+		}
+		if gt != -1 {
+			gtOffset = si.offsets.get(uint32(gt))
+		}
+		return
+	}
+
+	lt, eq, gt := si.SlimTrie.SearchExact(key, func(val interface{}) (string, bool) {
+		return keyOf(val.(int64))
+	})
+	if lt != nil {
+		ltOffset = lt.(int64)
+	}
+	if eq != nil {
+		eqOffset = eq.(int64)
+	}
+	if gt != nil {
+		gtOffset = gt.(int64)
+	}
+	return
+}
+
+// getFromBlock finds the block `key` might be in and reads `key` from it.
+//
+// SlimTrie does not store complete keys, thus for a key that is not a block
+// first key, the block SlimTrie locates might not be the right one:
+// words SlimTrie skips are not compared, and the greatest block first key <=
+// key might be far before or after the located block.
+//
+// It locates the block whose first key is the greatest one <= key with an
+// exact search of SlimTrie, which reads the first key of one block.
+// Thus it reads at most 2 blocks.
+func (si *SlimIndex) getFromBlock(key string) (string, bool) {
+
+	br, _ := asBlockReader(si.DataReader)
+
+	// the block read by the search, reused if it is the result.
+	readOffset := int64(-1)
+	var readKeys, readVals []string
+
+	lt, eq, _ := si.searchOffsetsExact(key, func(offset int64) (string, bool) {
+		keys, vals := br.ReadBlock(offset, si.blockSize)
+		if len(keys) == 0 {
+			return "", false
+		}
+		readOffset, readKeys, readVals = offset, keys, vals
+		return keys[0], true
+	})
+
+	c := eq
+	if c == -1 {
+		c = lt
+	}
+	if c == -1 {
 		return "", false
 	}
 
-	offset := o.(int64)
+	keys, vals := readKeys, readVals
+	if c != readOffset {
+		keys, vals = br.ReadBlock(c, si.blockSize)
+	}
 
-	return si.DataReader.Read(offset, key)
+	i := sort.SearchStrings(keys, key)
+	if i < len(keys) && keys[i] == key {
+		return vals[i], true
+	}
+
+	return "", false
+}
+
+// Marshal serializes options, SlimTrie, and offsets and weights stored out of
+// SlimTrie, if there are, to byte stream.
+//
+// It returns number of bytes written, and encountered error.
+func (si *SlimIndex) Marshal(writer io.Writer) (cnt int64, err error) {
+
+	opts := &prototype.SlimIndexOptions{
+		BlockSize:       int32(si.blockSize),
+		CompressOffsets: si.compressOffsets,
+		Weighted:        si.weighted,
+	}
+	if cnt, err = serialize.Marshal(writer, opts); err != nil {
+		return 0, err
+	}
+
+	n, err := si.SlimTrie.Marshal(writer)
+	if err != nil {
+		return 0, err
+	}
+	cnt += n
+
+	if si.compressOffsets {
+		n, err := serialize.Marshal(writer, si.offsets)
+		if err != nil {
+			return 0, err
+		}
+		cnt += n
+	}
+
+	if si.weighted {
+		for _, a := range []*array.Array32{&si.maxWeights, &si.leafWeights} {
+			n, err := serialize.Marshal(writer, a)
+			if err != nil {
+				return 0, err
+			}
+			cnt += n
+		}
+	}
+
+	return cnt, nil
+}
+
+// Unmarshal de-serializes and loads SlimIndex from a byte stream.
+//
+// Options BlockSize, CompressOffsets and Weighted are loaded from the byte
+// stream, e.g., an index created with `NewSlimIndex(nil, dr)` loads a block
+// mode index.
+// It returns ErrNotBlockReader if the loaded index is in block mode but the
+// DataReader is not a BlockReader.
+func (si *SlimIndex) Unmarshal(reader io.Reader) error {
+
+	opts := &prototype.SlimIndexOptions{}
+	if err := serialize.Unmarshal(reader, opts); err != nil {
+		return err
+	}
+
+	si.blockSize = int(opts.BlockSize)
+	si.compressOffsets = opts.CompressOffsets
+	si.weighted = opts.Weighted
+	si.offsets = nil
+
+	if err := si.checkOptions(); err != nil {
+		return err
+	}
+
+	if si.compressOffsets {
+		si.Leaves.Converter = emptyConv{}
+	} else {
+		si.Leaves.Converter = marshal.I64{}
+	}
+
+	if err := si.SlimTrie.Unmarshal(reader); err != nil {
+		return err
+	}
+
+	if si.compressOffsets {
+		si.offsets = &packedOffsets{}
+		if err := serialize.Unmarshal(reader, si.offsets); err != nil {
+			return err
+		}
+	}
+
+	if si.weighted {
+		for _, a := range []*array.Array32{&si.maxWeights, &si.leafWeights} {
+			if err := serialize.Unmarshal(reader, a); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
@@ -1,6 +1,8 @@
 package index_test
 
 import (
+	"bytes"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -65,3 +67,426 @@ func TestSlimIndex(t *testing.T) {
 	}
 
 }
+
+// testBlockData is a BlockReader of records "key,value,".
+// Its Read scans records from `offset` until it finds `key` or passes it.
+type testBlockData string
+
+func (d testBlockData) Read(offset int64, key string) (string, bool) {
+	kvs := strings.Split(string(d)[offset:], ",")
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if kvs[i] == key {
+			return kvs[i+1], true
+		}
+		if kvs[i] > key {
+			break
+		}
+	}
+	return "", false
+}
+
+func (d testBlockData) ReadBlock(offset int64, n int) ([]string, []string) {
+	if offset >= int64(len(d)) {
+		return nil, nil
+	}
+
+	kvs := strings.Split(string(d)[offset:], ",")
+	keys, vals := []string{}, []string{}
+	for i := 0; i+1 < len(kvs) && len(keys) < n; i += 2 {
+		keys = append(keys, kvs[i])
+		vals = append(vals, kvs[i+1])
+	}
+	return keys, vals
+}
+
+func makeTestBlockDataOf(keys []string) (testBlockData, []index.OffsetIndexItem) {
+	items := make([]index.OffsetIndexItem, 0, len(keys))
+	buf := make([]string, 0, len(keys))
+	offset := int64(0)
+	for i, k := range keys {
+		kv := fmt.Sprintf("%s,%d,", k, i)
+		items = append(items, index.OffsetIndexItem{Key: k, Offset: offset})
+		buf = append(buf, kv)
+		offset += int64(len(kv))
+	}
+	return testBlockData(strings.Join(buf, "")), items
+}
+
+func makeTestBlockData(n int) (testBlockData, []index.OffsetIndexItem) {
+	items := make([]index.OffsetIndexItem, 0, n)
+	buf := make([]string, 0, n)
+	offset := int64(0)
+	for i := 0; i < n; i++ {
+		k := fmt.Sprintf("k%05d", i*7)
+		kv := fmt.Sprintf("%s,%d,", k, i)
+		items = append(items, index.OffsetIndexItem{Key: k, Offset: offset})
+		buf = append(buf, kv)
+		offset += int64(len(kv))
+	}
+	return testBlockData(strings.Join(buf, "")), items
+}
+
+func TestSlimIndexBlock(t *testing.T) {
+
+	n := 1000
+	data, items := makeTestBlockData(n)
+
+	full, err := index.NewSlimIndex(items, data)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for _, blockSize := range []int{1, 2, 7, 40, 1000, 2000} {
+
+		st, err := index.NewSlimIndex(items, data, index.BlockSize(blockSize))
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		wantCnt := uint32(n)
+		if blockSize > 1 {
+			wantCnt = uint32((n + blockSize - 1) / blockSize)
+		}
+		if st.Leaves.Cnt != wantCnt {
+			t.Fatalf("blockSize=%d: want %d indexed keys but: %d",
+				blockSize, wantCnt, st.Leaves.Cnt)
+		}
+
+		if blockSize > 7 && len(st.Leaves.Elts)*blockSize/2 > len(full.Leaves.Elts) {
+			t.Fatalf("blockSize=%d: Leaves does not shrink: %d, full: %d",
+				blockSize, len(st.Leaves.Elts), len(full.Leaves.Elts))
+		}
+
+		for i := 0; i < n*7+10; i++ {
+			k := fmt.Sprintf("k%05d", i)
+			want := ""
+			wantfound := i%7 == 0 && i/7 < n
+			if wantfound {
+				want = fmt.Sprintf("%d", i/7)
+			}
+
+			rst, found := st.Get2(k)
+			if rst != want || found != wantfound {
+				t.Fatalf("blockSize=%d: input: %v; want: %v %v; actual: %v %v",
+					blockSize, k, want, wantfound, rst, found)
+			}
+		}
+
+		for _, k := range []string{"", "a", "k", "k0000", "k000000", "l"} {
+			_, found := st.Get2(k)
+			if found {
+				t.Fatalf("blockSize=%d: input: %v; should not be found", blockSize, k)
+			}
+		}
+	}
+
+	// empty index
+
+	for _, items := range [][]index.OffsetIndexItem{nil, {}} {
+
+		st, err := index.NewSlimIndex(items, data, index.BlockSize(4))
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for _, k := range []string{"", "a", "k00000"} {
+			if rst, found := st.Get2(k); found {
+				t.Fatalf("input: %v; should not be found in empty index but: %v", k, rst)
+			}
+		}
+	}
+}
+
+func TestSlimIndexBlockSkippedWords(t *testing.T) {
+
+	// SlimTrie skips words shared by keys in a sub-trie, such as the 2nd byte
+	// of "ca" and "cb".
+	// "cR" is routed into the sub-trie of "ca" and "cb", while the block it
+	// is in starts with "aa".
+	keys := []string{"aa", "cR", "ca", "caa", "cb", "cbb"}
+	data, items := makeTestBlockDataOf(keys)
+
+	for _, opts := range [][]index.Option{
+		{index.BlockSize(2)},
+		{index.BlockSize(2), index.CompressOffsets()},
+		{index.BlockSize(3)},
+	} {
+
+		st, err := index.NewSlimIndex(items, data, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for i, k := range keys {
+			rst, found := st.Get2(k)
+			if !found || rst != fmt.Sprintf("%d", i) {
+				t.Fatalf("input: %v; want: %d; actual: %v %v", k, i, rst, found)
+			}
+		}
+
+		for _, k := range []string{"", "a", "ab", "b", "cQ", "cS", "cZ",
+			"caaa", "cab", "cba", "cbc", "cq", "cr", "d"} {
+			rst, found := st.Get2(k)
+			if found {
+				t.Fatalf("input: %v; should not be found but: %v", k, rst)
+			}
+		}
+	}
+}
+
+func TestSlimIndexBlockReads(t *testing.T) {
+
+	// All keys but "x" share "yz", which SlimTrie skips.
+	// An absent key such as "ya3000" is routed deep into the sub-trie of
+	// "yz...", while the block it would be in starts with "x".
+	keys := []string{"x"}
+	for i := 0; i < 4000; i++ {
+		keys = append(keys, fmt.Sprintf("yz%04d", i))
+	}
+	blockData, items := makeTestBlockDataOf(keys)
+	data := &countingBlockData{testBlockData: blockData}
+
+	for _, opts := range [][]index.Option{
+		{index.BlockSize(4)},
+		{index.BlockSize(4), index.CompressOffsets()},
+	} {
+
+		st, err := index.NewSlimIndex(items, data, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for _, k := range []string{"x", "yz0000", "yz1234", "yz3999"} {
+			data.blockReads = 0
+			if _, found := st.Get2(k); !found {
+				t.Fatalf("input: %v; expect found", k)
+			}
+			if data.blockReads > 2 {
+				t.Fatalf("input: %v; expect at most 2 block reads but: %d", k, data.blockReads)
+			}
+		}
+
+		for _, k := range []string{"", "w", "x0", "ya3000", "y{3000", "yz", "yz3000a", "yz4000", "z"} {
+			data.blockReads = 0
+			if rst, found := st.Get2(k); found {
+				t.Fatalf("input: %v; should not be found but: %v", k, rst)
+			}
+			if data.blockReads > 2 {
+				t.Fatalf("input: %v; expect at most 2 block reads but: %d", k, data.blockReads)
+			}
+		}
+	}
+}
+
+func TestSlimIndexNotBlockReader(t *testing.T) {
+
+	data := testIndexData("Aaron,1,Agatha,1,")
+	items := []index.OffsetIndexItem{
+		{Key: "Aaron", Offset: 0},
+		{Key: "Agatha", Offset: 8},
+	}
+
+	_, err := index.NewSlimIndex(items, data, index.BlockSize(2))
+	if err != index.ErrNotBlockReader {
+		t.Fatalf("expect ErrNotBlockReader but: %v", err)
+	}
+}
+
+func TestSlimIndexCompressOffsets(t *testing.T) {
+
+	n := 1000
+	data, items := makeTestBlockData(n)
+
+	full, err := index.NewSlimIndex(items, data)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	for _, opts := range [][]index.Option{
+		{index.CompressOffsets()},
+		{index.CompressOffsets(), index.BlockSize(16)},
+	} {
+
+		st, err := index.NewSlimIndex(items, data, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		if len(st.Leaves.Elts) != 0 {
+			t.Fatalf("expect no offset in Leaves but: %d bytes", len(st.Leaves.Elts))
+		}
+
+		for i := 0; i < n*7+10; i++ {
+			k := fmt.Sprintf("k%05d", i)
+
+			want, wantfound := full.Get2(k)
+			rst, found := st.Get2(k)
+			if rst != want || found != wantfound {
+				t.Fatalf("input: %v; want: %v %v; actual: %v %v",
+					k, want, wantfound, rst, found)
+			}
+		}
+	}
+}
+
+func TestSlimIndexMarshalUnmarshal(t *testing.T) {
+
+	n := 1000
+	data, items := makeTestBlockData(n)
+
+	for _, opts := range [][]index.Option{
+		{},
+		{index.BlockSize(16)},
+		{index.CompressOffsets()},
+		{index.CompressOffsets(), index.BlockSize(16)},
+	} {
+
+		st, err := index.NewSlimIndex(items, data, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		buf := new(bytes.Buffer)
+		cnt, err := st.Marshal(buf)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+		if cnt != int64(buf.Len()) {
+			t.Fatalf("expect %d bytes written but: %d", buf.Len(), cnt)
+		}
+
+		serialized := buf.Bytes()
+
+		// options are loaded from serialized data
+		for _, loadOpts := range [][]index.Option{opts, {}, {index.CompressOffsets()}} {
+
+			loaded, err := index.NewSlimIndex(nil, data, loadOpts...)
+			if err != nil {
+				t.Fatalf("expect no error but: %s", err)
+			}
+
+			err = loaded.Unmarshal(bytes.NewReader(serialized))
+			if err != nil {
+				t.Fatalf("expect no error but: %s", err)
+			}
+
+			for i := 0; i < n*7+10; i++ {
+				k := fmt.Sprintf("k%05d", i)
+
+				want, wantfound := st.Get2(k)
+				rst, found := loaded.Get2(k)
+				if rst != want || found != wantfound {
+					t.Fatalf("input: %v; want: %v %v; actual: %v %v",
+						k, want, wantfound, rst, found)
+				}
+			}
+		}
+	}
+
+	// a block mode index requires a BlockReader to load
+
+	st, err := index.NewSlimIndex(items, data, index.BlockSize(16))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := st.Marshal(buf); err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	loaded, err := index.NewSlimIndex(nil, testIndexData(data))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if err := loaded.Unmarshal(buf); err != index.ErrNotBlockReader {
+		t.Fatalf("expect ErrNotBlockReader but: %v", err)
+	}
+}
+
+func TestSlimIndexBlockSize(t *testing.T) {
+
+	data, items := makeTestBlockData(10)
+
+	for _, n := range []int{0, -1, -100} {
+		_, err := index.NewSlimIndex(items, data, index.BlockSize(n))
+		if err != index.ErrBlockSize {
+			t.Fatalf("BlockSize(%d): expect ErrBlockSize but: %v", n, err)
+		}
+	}
+}
+
+func TestSlimIndexSeek(t *testing.T) {
+
+	keys := []string{"aa", "cR", "ca", "caa", "cb", "cbb"}
+
+	data := testVersionedData{}
+	items := []index.OffsetIndexItem{}
+	for i, k := range keys {
+		data = append(data, [2]string{k, ""})
+		items = append(items, index.OffsetIndexItem{Key: k, Offset: int64(i)})
+	}
+
+	cases := []struct {
+		key       string
+		want      int64
+		wantfound bool
+	}{
+		{"", 0, true},
+		{"aa", 0, true},
+		{"ab", 1, true},
+		{"cR", 1, true},
+		{"cS", 2, true},
+		{"cZ", 2, true},
+		{"ca", 2, true},
+		{"caaa", 4, true},
+		{"cba", 5, true},
+		{"cbb", 5, true},
+		{"cbc", 0, false},
+		{"d", 0, false},
+	}
+
+	for _, opts := range [][]index.Option{
+		{},
+		{index.CompressOffsets()},
+	} {
+
+		st, err := index.NewSlimIndex(items, data, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for i, c := range cases {
+			rst, found := st.Seek(c.key)
+			if rst != c.want || found != c.wantfound {
+				t.Fatalf("%d-th: input: %q; want: %v %v; actual: %v %v",
+					i+1, c.key, c.want, c.wantfound, rst, found)
+			}
+		}
+	}
+
+	// not a KeyValueReader
+
+	st, err := index.NewSlimIndex(items, testIndexData(""))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if _, found := st.Seek("a"); found {
+		t.Fatalf("expect not found without KeyValueReader")
+	}
+
+	// empty index
+
+	for _, items := range [][]index.OffsetIndexItem{nil, {}} {
+
+		st, err := index.NewSlimIndex(items, testVersionedData{})
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for _, k := range []string{"", "a", "cb"} {
+			if rst, found := st.Seek(k); found {
+				t.Fatalf("input: %q; expect not found in empty index but: %v", k, rst)
+			}
+		}
+	}
+}
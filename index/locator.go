@@ -0,0 +1,171 @@
+package index
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/openacid/slim/trie"
+)
+
+// ErrUnknownFileID is returned if a Locator refers to a file that a
+// FileSetReader does not have.
+var ErrUnknownFileID = errors.New("unknown file id")
+
+// Locator describes where a record is: in which file, at what offset and how
+// many bytes it takes.
+type Locator struct {
+	// FileID identifies the file the record is in.
+	FileID uint32
+	// Offset is the position of the record in its file.
+	Offset uint64
+	// Length is the size in byte of the record.
+	Length uint32
+}
+
+// LocatorIndexItem defines data types for a locator-based index, such as an
+// index of records spread over several files.
+type LocatorIndexItem struct {
+	// Key is the record identity.
+	Key string
+	Locator
+}
+
+// LocatorReader defines interface to let LocatorIndex access the data it
+// indexes.
+type LocatorReader interface {
+	// ReadLocator reads value at `loc`, of `key`.
+	// Like DataReader, it is data providers' responsibility to check if the
+	// record at `loc` has the exact `key`.
+	ReadLocator(loc Locator, key string) (string, bool)
+}
+
+// LocatorConv implements array.Converter and converts a Locator to a slice of
+// 16 bytes and back.
+//
+// The fields are packed without padding:
+//
+//	FileID: 4 bytes
+//	Offset: 8 bytes
+//	Length: 4 bytes
+type LocatorConv struct{}
+
+// locatorSize is the marshaled size of a Locator.
+const locatorSize = 4 + 8 + 4
+
+// Marshal converts a Locator to a slice of 16 bytes.
+func (c LocatorConv) Marshal(d interface{}) []byte {
+	loc := d.(Locator)
+
+	b := make([]byte, locatorSize)
+	binary.LittleEndian.PutUint32(b[0:4], loc.FileID)
+	binary.LittleEndian.PutUint64(b[4:12], loc.Offset)
+	binary.LittleEndian.PutUint32(b[12:16], loc.Length)
+	return b
+}
+
+// Unmarshal converts slice of 16 bytes to a Locator.
+// It returns number bytes consumed and a Locator.
+func (c LocatorConv) Unmarshal(b []byte) (int, interface{}) {
+	loc := Locator{
+		FileID: binary.LittleEndian.Uint32(b[0:4]),
+		Offset: binary.LittleEndian.Uint64(b[4:12]),
+		Length: binary.LittleEndian.Uint32(b[12:16]),
+	}
+	return locatorSize, loc
+}
+
+// GetMarshaledSize returns 16.
+func (c LocatorConv) GetMarshaledSize(b []byte) int {
+	return locatorSize
+}
+
+// LocatorIndex contains a SlimTrie instance mapping keys to Locators and a
+// data provider `LocatorReader`.
+type LocatorIndex struct {
+	trie.SlimTrie
+	LocatorReader
+}
+
+// NewLocatorIndex creates LocatorIndex instance.
+//
+// The keys in `index` must be in ascending order.
+func NewLocatorIndex(index []LocatorIndexItem, lr LocatorReader) (*LocatorIndex, error) {
+
+	l := len(index)
+	keys := make([]string, 0, l)
+	locs := make([]Locator, 0, l)
+	for i := 0; i < l; i++ {
+		keys = append(keys, index[i].Key)
+		locs = append(locs, index[i].Locator)
+	}
+
+	st, err := trie.NewSlimTrie(LocatorConv{}, keys, locs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocatorIndex{*st, lr}, nil
+}
+
+// GetLocator returns the Locator of `key` and a bool indicating if there is
+// a record that possibly has `key`.
+func (li *LocatorIndex) GetLocator(key string) (Locator, bool) {
+	l := li.SlimTrie.Get(key)
+	if l == nil {
+		return Locator{}, false
+	}
+
+	return l.(Locator), true
+}
+
+// Get2 returns the value of `key` which is found by
+// `LocatorIndex.LocatorReader`, and a bool value indicating if the `key` is
+// found or not.
+func (li *LocatorIndex) Get2(key string) (string, bool) {
+	loc, ok := li.GetLocator(key)
+	if !ok {
+		return "", false
+	}
+
+	return li.LocatorReader.ReadLocator(loc, key)
+}
+
+// FileSetReader implements LocatorReader and routes every read to one of
+// several files by Locator.FileID.
+//
+// A record is read in one ReadAt call of exactly Locator.Length bytes, and is
+// then passed to Decode to check the key and to extract the value.
+type FileSetReader struct {
+	// Files are the data files, indexed by Locator.FileID.
+	Files []io.ReaderAt
+
+	// Decode checks if `record` has `key` and returns the value in it.
+	Decode func(record []byte, key string) (string, bool)
+}
+
+// ReadRecord reads the raw bytes of the record at `loc`.
+func (fr *FileSetReader) ReadRecord(loc Locator) ([]byte, error) {
+	if uint64(loc.FileID) >= uint64(len(fr.Files)) {
+		return nil, ErrUnknownFileID
+	}
+
+	buf := make([]byte, loc.Length)
+	n, err := fr.Files[loc.FileID].ReadAt(buf, int64(loc.Offset))
+	if n == len(buf) {
+		// io.ReaderAt may return io.EOF along with a full read at the end of a
+		// file.
+		return buf, nil
+	}
+	return nil, err
+}
+
+// ReadLocator implements LocatorReader.
+func (fr *FileSetReader) ReadLocator(loc Locator, key string) (string, bool) {
+	record, err := fr.ReadRecord(loc)
+	if err != nil {
+		return "", false
+	}
+
+	return fr.Decode(record, key)
+}
@@ -0,0 +1,119 @@
+package index_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/openacid/slim/index"
+)
+
+func decodeTestRecord(record []byte, key string) (string, bool) {
+	kv := strings.Split(string(record), ",")
+	if kv[0] == key {
+		return kv[1], true
+	}
+	return "", false
+}
+
+func TestLocatorConv(t *testing.T) {
+
+	cases := []index.Locator{
+		{},
+		{FileID: 1, Offset: 2, Length: 3},
+		{FileID: 0xffffffff, Offset: 0xffffffffffffffff, Length: 0xffffffff},
+	}
+
+	c := index.LocatorConv{}
+
+	for i, loc := range cases {
+		b := c.Marshal(loc)
+		if len(b) != 16 {
+			t.Fatalf("%d-th: marshaled size: want: 16; actual: %d", i+1, len(b))
+		}
+
+		if c.GetMarshaledSize(b) != 16 {
+			t.Fatalf("%d-th: GetMarshaledSize: want: 16; actual: %d",
+				i+1, c.GetMarshaledSize(b))
+		}
+
+		n, rst := c.Unmarshal(b)
+		if n != 16 {
+			t.Fatalf("%d-th: unmarshaled size: want: 16; actual: %d", i+1, n)
+		}
+		if rst.(index.Locator) != loc {
+			t.Fatalf("%d-th: want: %v; actual: %v", i+1, loc, rst)
+		}
+	}
+}
+
+func TestLocatorIndex(t *testing.T) {
+
+	files := []string{
+		"Aaron,1Al,2Alexander,5",
+		"Agatha,1Albert,3Alison,8",
+	}
+
+	dr := &index.FileSetReader{
+		Files: []io.ReaderAt{
+			strings.NewReader(files[0]),
+			strings.NewReader(files[1]),
+		},
+		Decode: decodeTestRecord,
+	}
+
+	items := []index.LocatorIndexItem{
+		{Key: "Aaron", Locator: index.Locator{FileID: 0, Offset: 0, Length: 7}},
+		{Key: "Agatha", Locator: index.Locator{FileID: 1, Offset: 0, Length: 8}},
+		{Key: "Al", Locator: index.Locator{FileID: 0, Offset: 7, Length: 4}},
+		{Key: "Albert", Locator: index.Locator{FileID: 1, Offset: 8, Length: 8}},
+		{Key: "Alexander", Locator: index.Locator{FileID: 0, Offset: 11, Length: 11}},
+		{Key: "Alison", Locator: index.Locator{FileID: 1, Offset: 16, Length: 8}},
+	}
+
+	li, err := index.NewLocatorIndex(items, dr)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	cases := []struct {
+		input     string
+		want      string
+		wantfound bool
+	}{
+		{"Aaron", "1", true},
+		{"Agatha", "1", true},
+		{"Al", "2", true},
+		{"Albert", "3", true},
+		{"Alexander", "5", true},
+		{"Alison", "8", true},
+		{"foo", "", false},
+		{"Alexande", "", false},
+		{"Alexander0", "", false},
+		{"alexander", "", false},
+	}
+
+	for i, c := range cases {
+		rst, found := li.Get2(c.input)
+		if rst != c.want {
+			t.Fatalf("%d-th: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.want, rst)
+		}
+		if found != c.wantfound {
+			t.Fatalf("%d-th: input: %v; wantfound: %v; actual: %v",
+				i+1, c.input, c.wantfound, found)
+		}
+	}
+
+	// a Locator referring to an absent file
+	dr.Files = dr.Files[:1]
+	_, found := li.Get2("Agatha")
+	if found {
+		t.Fatalf("expect not found with unknown file id")
+	}
+
+	_, err = dr.ReadRecord(index.Locator{FileID: 1})
+	if err != index.ErrUnknownFileID {
+		t.Fatalf("expect ErrUnknownFileID but: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package index
+
+import (
+	"github.com/openacid/slim/prototype"
+)
+
+// offsetBlockSize is the number of offsets in a frame-of-reference block.
+const offsetBlockSize = 64
+
+// packedOffsets stores offsets with frame-of-reference encoding.
+//
+// Offsets are split into blocks of 64.
+// For every block it stores the min offset in `Bases`, and every offset minus
+// the min offset, in `w` bits, where `w` is the bit width of the greatest of
+// them.
+//
+// A block of 64 w-bit numbers takes exactly `w` words.
+// Thus the bit width of block `i` is `Starts[i+1] - Starts[i]`.
+//
+// An offset is located by its position, e.g. the ordinal of a SlimTrie leaf.
+// Leaf ordinals follow SlimTrie node id order, not key order, thus offsets in a
+// block are not monotone and the width of a block is usually close to the bit
+// width of the whole file span.
+type packedOffsets struct {
+	prototype.PackedOffsets
+}
+
+// newPackedOffsets creates a packedOffsets from a slice of offsets.
+func newPackedOffsets(offsets []int64) *packedOffsets {
+
+	n := len(offsets)
+	nBlocks := (n + offsetBlockSize - 1) / offsetBlockSize
+
+	p := &packedOffsets{}
+	p.Cnt = uint32(n)
+	p.Bases = make([]uint64, 0, nBlocks)
+	p.Starts = make([]uint32, 0, nBlocks+1)
+	p.Starts = append(p.Starts, 0)
+
+	for s := 0; s < n; s += offsetBlockSize {
+		e := s + offsetBlockSize
+		if e > n {
+			e = n
+		}
+		block := offsets[s:e]
+
+		base, max := uint64(block[0]), uint64(block[0])
+		for _, o := range block {
+			if uint64(o) < base {
+				base = uint64(o)
+			}
+			if uint64(o) > max {
+				max = uint64(o)
+			}
+		}
+
+		width := uint32(0)
+		for d := max - base; d != 0; d >>= 1 {
+			width++
+		}
+
+		start := uint32(len(p.Words))
+		p.Words = append(p.Words, make([]uint64, width)...)
+		for i, o := range block {
+			p.set(start, width, uint32(i), uint64(o)-base)
+		}
+
+		p.Bases = append(p.Bases, base)
+		p.Starts = append(p.Starts, start+width)
+	}
+
+	return p
+}
+
+// set puts `v` at the `i`-th `width`-bit number starting from Words[start].
+func (p *packedOffsets) set(start, width, i uint32, v uint64) {
+	if width == 0 {
+		return
+	}
+
+	bitPos := i * width
+	iWord, iBit := start+bitPos>>6, bitPos&63
+
+	p.Words[iWord] |= v << iBit
+	if iBit+width > 64 {
+		p.Words[iWord+1] |= v >> (64 - iBit)
+	}
+}
+
+// get returns the `i`-th offset.
+func (p *packedOffsets) get(i uint32) int64 {
+	iBlock := i / offsetBlockSize
+
+	start := p.Starts[iBlock]
+	width := p.Starts[iBlock+1] - start
+	base := p.Bases[iBlock]
+	if width == 0 {
+		return int64(base)
+	}
+
+	bitPos := (i % offsetBlockSize) * width
+	iWord, iBit := start+bitPos>>6, bitPos&63
+
+	v := p.Words[iWord] >> iBit
+	if iBit+width > 64 {
+		v |= p.Words[iWord+1] << (64 - iBit)
+	}
+
+	mask := (uint64(1) << width) - 1
+	return int64(base + v&mask)
+}
+
+// emptyConv implements array.Converter and stores nothing.
+// It is used for SlimTrie.Leaves when values are stored outside SlimTrie.
+type emptyConv struct{}
+
+func (c emptyConv) Marshal(d interface{}) []byte {
+	return []byte{}
+}
+
+func (c emptyConv) Unmarshal(b []byte) (int, interface{}) {
+	return 0, nil
+}
+
+func (c emptyConv) GetMarshaledSize(b []byte) int {
+	return 0
+}
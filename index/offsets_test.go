@@ -0,0 +1,51 @@
+package index
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPackedOffsets(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	cases := [][]int64{
+		{},
+		{0},
+		{5, 5, 5},
+		{1 << 62, 0, 1<<63 - 1},
+	}
+
+	monotone := make([]int64, 1000)
+	for i := 1; i < len(monotone); i++ {
+		monotone[i] = monotone[i-1] + rnd.Int63n(200)
+	}
+	cases = append(cases, monotone)
+
+	random := make([]int64, 1000)
+	for i := range random {
+		random[i] = rnd.Int63()
+	}
+	cases = append(cases, random)
+
+	for i, offsets := range cases {
+		p := newPackedOffsets(offsets)
+
+		if p.Cnt != uint32(len(offsets)) {
+			t.Fatalf("%d-th: Cnt: want: %d; actual: %d", i+1, len(offsets), p.Cnt)
+		}
+
+		for j, want := range offsets {
+			rst := p.get(uint32(j))
+			if rst != want {
+				t.Fatalf("%d-th: get(%d): want: %d; actual: %d", i+1, j, want, rst)
+			}
+		}
+	}
+
+	// 200 < 2^8, every monotone offset takes at most 8+6 bits.
+	p := newPackedOffsets(monotone)
+	if len(p.Words)*64 > len(monotone)*14 {
+		t.Fatalf("monotone offsets take too many words: %d", len(p.Words))
+	}
+}
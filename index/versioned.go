@@ -0,0 +1,117 @@
+package index
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// KeyValueReader reads the complete key and the value of a record.
+type KeyValueReader interface {
+	// ReadKeyValue returns the key and value of the record at `offset`, and a
+	// bool indicating if there is a record.
+	ReadKeyValue(offset int64) (key, value string, found bool)
+}
+
+// VersionKey encodes a key and a timestamp into a single index key.
+//
+// Index keys of the same `key` are adjacent and sorted by timestamp in
+// descending order.
+// Because the byte order of encoded keys is the same as the order of
+// (key, reverse timestamp):
+//
+//	key:       every 0x00 in key is escaped to 0x00 0xff
+//	delimiter: 0x00 0x01
+//	timestamp: ^ts in 8-byte big-endian
+func VersionKey(key string, ts uint64) string {
+	b := make([]byte, 0, len(key)+2+8)
+	for i := 0; i < len(key); i++ {
+		b = append(b, key[i])
+		if key[i] == 0x00 {
+			b = append(b, 0xff)
+		}
+	}
+	b = append(b, 0x00, 0x01)
+
+	var t [8]byte
+	binary.BigEndian.PutUint64(t[:], ^ts)
+	b = append(b, t[:]...)
+
+	return string(b)
+}
+
+// ParseVersionKey decodes an index key built by VersionKey into key and
+// timestamp.
+// The last returned value is false if `vkey` is not a valid encoded key.
+func ParseVersionKey(vkey string) (string, uint64, bool) {
+	l := len(vkey)
+	if l < 2+8 || vkey[l-10:l-8] != "\x00\x01" {
+		return "", 0, false
+	}
+
+	escaped := vkey[:l-10]
+	key := strings.Replace(escaped, "\x00\xff", "\x00", -1)
+	if strings.Count(key, "\x00") != strings.Count(escaped, "\x00\xff") {
+		return "", 0, false
+	}
+
+	ts := ^binary.BigEndian.Uint64([]byte(vkey[l-8:]))
+
+	return key, ts, true
+}
+
+// GetAsOf returns the value of the newest version of `key` whose timestamp is
+// not greater than `ts`, and a bool indicating if there is such a version.
+//
+// Versions must be indexed with keys built by `VersionKey(key, ts)`, and
+// `SlimIndex.DataReader` must be a KeyValueReader, which returns the index
+// keys.
+// Block mode is not supported.
+//
+// It locates the smallest index key >= VersionKey(key, ts) with an exact
+// search of SlimTrie, which reads the key of one record.
+// Thus it reads at most 2 records, no matter how many versions there are.
+func (si *SlimIndex) GetAsOf(key string, ts uint64) (string, bool) {
+
+	kvr, ok := asKeyValueReader(si.DataReader)
+	if !ok || si.blockSize > 1 {
+		return "", false
+	}
+
+	q := VersionKey(key, ts)
+
+	// the record read by the search, reused if it is the result.
+	readOffset := int64(-1)
+	var readKey, readVal string
+
+	_, eq, gt := si.searchOffsetsExact(q, func(offset int64) (string, bool) {
+		k, v, found := kvr.ReadKeyValue(offset)
+		if !found {
+			return "", false
+		}
+		readOffset, readKey, readVal = offset, k, v
+		return k, true
+	})
+
+	c := eq
+	if c == -1 {
+		c = gt
+	}
+	if c == -1 {
+		return "", false
+	}
+
+	ck, cv := readKey, readVal
+	if c != readOffset {
+		var found bool
+		ck, cv, found = kvr.ReadKeyValue(c)
+		if !found {
+			return "", false
+		}
+	}
+
+	k, _, ok := ParseVersionKey(ck)
+	if !ok || k != key {
+		return "", false
+	}
+	return cv, true
+}
@@ -0,0 +1,245 @@
+package index_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/openacid/slim/index"
+)
+
+// testVersionedData stores records of version keys and values, an offset is
+// the position of a record.
+type testVersionedData [][2]string
+
+func (d testVersionedData) Read(offset int64, key string) (string, bool) {
+	k, v, found := d.ReadKeyValue(offset)
+	if !found || k != key {
+		return "", false
+	}
+	return v, true
+}
+
+func (d testVersionedData) ReadKeyValue(offset int64) (string, string, bool) {
+	if offset < 0 || offset >= int64(len(d)) {
+		return "", "", false
+	}
+	return d[offset][0], d[offset][1], true
+}
+
+func TestVersionKey(t *testing.T) {
+
+	cases := []struct {
+		key string
+		ts  uint64
+	}{
+		{"", 0},
+		{"a", 1},
+		{"a\x00", 2},
+		{"\x00\xff\x00", math.MaxUint64},
+		{"abc\x01", 1 << 40},
+	}
+
+	for i, c := range cases {
+		vk := index.VersionKey(c.key, c.ts)
+		key, ts, ok := index.ParseVersionKey(vk)
+		if !ok || key != c.key || ts != c.ts {
+			t.Fatalf("%d-th: input: %q %v; actual: %q %v %v",
+				i+1, c.key, c.ts, key, ts, ok)
+		}
+	}
+
+	// order: by key, then by timestamp descending
+
+	ordered := []string{
+		index.VersionKey("a", 9),
+		index.VersionKey("a", 1),
+		index.VersionKey("a\x00", math.MaxUint64),
+		index.VersionKey("a\x00", 0),
+		index.VersionKey("a\x00b", 5),
+		index.VersionKey("a\x01", 5),
+		index.VersionKey("ab", 5),
+	}
+	if !sort.StringsAreSorted(ordered) {
+		t.Fatalf("expect version keys to be sorted: %q", ordered)
+	}
+
+	for _, vk := range []string{"", "a", "a\x00\x01", "a\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00"} {
+		if _, _, ok := index.ParseVersionKey(vk); ok {
+			t.Fatalf("expect invalid version key: %q", vk)
+		}
+	}
+}
+
+func TestSlimIndexGetAsOf(t *testing.T) {
+
+	versions := []struct {
+		key string
+		ts  uint64
+		val string
+	}{
+		{"a", 1, "a1"},
+		{"a", 5, "a5"},
+		{"a", 10, "a10"},
+		{"a\x00", 3, "a0-3"},
+		{"b", 0, "b0"},
+		{"b", math.MaxUint64, "bmax"},
+		{"c", 7, "c7"},
+	}
+
+	data := testVersionedData{}
+	for _, v := range versions {
+		data = append(data, [2]string{index.VersionKey(v.key, v.ts), v.val})
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i][0] < data[j][0] })
+
+	items := []index.OffsetIndexItem{}
+	for i, r := range data {
+		items = append(items, index.OffsetIndexItem{Key: r[0], Offset: int64(i)})
+	}
+
+	cases := []struct {
+		key       string
+		ts        uint64
+		want      string
+		wantfound bool
+	}{
+		{"a", 0, "", false},
+		{"a", 1, "a1", true},
+		{"a", 4, "a1", true},
+		{"a", 5, "a5", true},
+		{"a", 9, "a5", true},
+		{"a", 10, "a10", true},
+		{"a", math.MaxUint64, "a10", true},
+		{"a\x00", 2, "", false},
+		{"a\x00", 3, "a0-3", true},
+		{"a\x00", 100, "a0-3", true},
+		{"b", 0, "b0", true},
+		{"b", 100, "b0", true},
+		{"b", math.MaxUint64, "bmax", true},
+		{"c", 6, "", false},
+		{"c", 7, "c7", true},
+		{"", 100, "", false},
+		{"aa", 100, "", false},
+		{"d", 100, "", false},
+	}
+
+	for _, opts := range [][]index.Option{
+		{},
+		{index.CompressOffsets()},
+	} {
+
+		st, err := index.NewSlimIndex(items, data, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for i, c := range cases {
+			rst, found := st.GetAsOf(c.key, c.ts)
+			if rst != c.want || found != c.wantfound {
+				t.Fatalf("%d-th: input: %q %v; want: %v %v; actual: %v %v",
+					i+1, c.key, c.ts, c.want, c.wantfound, rst, found)
+			}
+		}
+	}
+
+	// not a KeyValueReader
+
+	st, err := index.NewSlimIndex(items, testIndexData(""))
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if _, found := st.GetAsOf("a", 5); found {
+		t.Fatalf("expect not found without KeyValueReader")
+	}
+
+	// empty index
+
+	for _, items := range [][]index.OffsetIndexItem{nil, {}} {
+
+		st, err := index.NewSlimIndex(items, testVersionedData{})
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for _, c := range cases {
+			if rst, found := st.GetAsOf(c.key, c.ts); found {
+				t.Fatalf("input: %q %v; expect not found in empty index but: %v",
+					c.key, c.ts, rst)
+			}
+		}
+	}
+}
+
+// countingVersionedData counts records read.
+type countingVersionedData struct {
+	testVersionedData
+	reads int
+}
+
+func (d *countingVersionedData) ReadKeyValue(offset int64) (string, string, bool) {
+	d.reads++
+	return d.testVersionedData.ReadKeyValue(offset)
+}
+
+func TestSlimIndexGetAsOfReads(t *testing.T) {
+
+	// a hot key with many versions, and keys sharing words SlimTrie skips.
+	data := testVersionedData{}
+	for ts := uint64(0); ts < 1000; ts++ {
+		data = append(data, [2]string{index.VersionKey("hot", ts*2), "hot"})
+	}
+	for _, k := range []string{"aa", "cR", "ca", "caa", "cb", "cbb", "hot\x00", "hotter"} {
+		data = append(data, [2]string{index.VersionKey(k, 10), k})
+	}
+	sort.Slice(data, func(i, j int) bool { return data[i][0] < data[j][0] })
+
+	items := []index.OffsetIndexItem{}
+	for i, r := range data {
+		items = append(items, index.OffsetIndexItem{Key: r[0], Offset: int64(i)})
+	}
+
+	// brute force: the smallest index key >= VersionKey(key, ts).
+	want := func(key string, ts uint64) (string, bool) {
+		q := index.VersionKey(key, ts)
+		i := sort.Search(len(data), func(i int) bool { return data[i][0] >= q })
+		if i == len(data) {
+			return "", false
+		}
+		k, _, _ := index.ParseVersionKey(data[i][0])
+		if k != key {
+			return "", false
+		}
+		return data[i][1], true
+	}
+
+	for _, opts := range [][]index.Option{
+		{},
+		{index.CompressOffsets()},
+	} {
+
+		d := &countingVersionedData{testVersionedData: data}
+		st, err := index.NewSlimIndex(items, d, opts...)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		for _, key := range []string{"hot", "aa", "cR", "ca", "caa", "cb", "cbb", "c", "cc", "hot\x00", "hotter", "ho", "z"} {
+			for _, ts := range []uint64{0, 1, 9, 10, 11, 999, 1998, 1999, math.MaxUint64} {
+
+				d.reads = 0
+				rst, found := st.GetAsOf(key, ts)
+
+				w, wfound := want(key, ts)
+				if rst != w || found != wfound {
+					t.Fatalf("input: %q %v; want: %v %v; actual: %v %v",
+						key, ts, w, wfound, rst, found)
+				}
+				if d.reads > 2 {
+					t.Fatalf("input: %q %v; expect at most 2 reads but: %d",
+						key, ts, d.reads)
+				}
+			}
+		}
+	}
+}
@@ -5,6 +5,7 @@ package marshal
 import (
 	"encoding/binary"
 	"errors"
+	"math"
 	"reflect"
 )
 
@@ -30,37 +31,102 @@ type Marshaller interface {
 	GetMarshaledSize([]byte) int
 }
 
-// GetMarshaller returns a `Marshaller` implementation for type of `e`
+// GetMarshaller returns a `Marshaller` implementation for type of `e`.
+//
+// Every fixed-size kind is supported: uint8 to uint64, int8 to int64,
+// float32, float64 and bool, as well as string, []byte and arrays of supported
+// types, such as [4]uint16.
+// A named type of these kinds, such as `type Tier uint8`, is supported with a
+// Named Marshaller.
+// int and uint are not supported since their size depends on platform.
 func GetMarshaller(e interface{}) (Marshaller, error) {
-	k := reflect.ValueOf(e).Kind()
-	return getMarshallerByKind(k)
+	return getMarshallerByType(reflect.TypeOf(e))
 }
 
-// GetMarshaller returns a `Marshaller` implementation for element type of slice `s`
+// GetSliceEltMarshaller returns a `Marshaller` implementation for element type
+// of slice `s`
 func GetSliceEltMarshaller(s interface{}) (Marshaller, error) {
 	sl := reflect.ValueOf(s)
 	if sl.Kind() != reflect.Slice {
 		return nil, ErrNotSlice
 	}
 
-	eltKind := reflect.TypeOf(s).Elem().Kind()
+	return getMarshallerByType(sl.Type().Elem())
+}
 
-	return getMarshallerByKind(eltKind)
+// builtinTypes maps a kind to the builtin type a Marshaller of this kind
+// accepts.
+// Slice is only supported for []byte.
+var builtinTypes = map[reflect.Kind]reflect.Type{
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.String:  reflect.TypeOf(""),
+	reflect.Slice:   reflect.TypeOf([]byte{}),
 }
 
-func getMarshallerByKind(k reflect.Kind) (Marshaller, error) {
+func getMarshallerByType(t reflect.Type) (Marshaller, error) {
+	if t == nil {
+		return nil, ErrUnknownEltType
+	}
+
 	var m Marshaller
-	switch k {
+	switch t.Kind() {
+	case reflect.Uint8:
+		m = U8{}
 	case reflect.Uint16:
 		m = U16{}
 	case reflect.Uint32:
 		m = U32{}
 	case reflect.Uint64:
 		m = U64{}
+	case reflect.Int8:
+		m = I8{}
+	case reflect.Int16:
+		m = I16{}
+	case reflect.Int32:
+		m = I32{}
+	case reflect.Int64:
+		m = I64{}
+	case reflect.Float32:
+		m = F32{}
+	case reflect.Float64:
+		m = F64{}
+	case reflect.Bool:
+		m = Bool{}
+	case reflect.String:
+		m = String16{}
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return nil, ErrUnknownEltType
+		}
+		m = Bytes16{}
+	case reflect.Array:
+		elt, err := getMarshallerByType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		m = Array{Type: t, Elt: elt}
 	default:
 		return nil, ErrUnknownEltType
 	}
 
+	if bt, ok := builtinTypes[t.Kind()]; ok && t != bt {
+		// E.g. []MyByte can not be converted to []byte.
+		if !t.ConvertibleTo(bt) {
+			return nil, ErrUnknownEltType
+		}
+		m = Named{Type: t, Elt: m}
+	}
+
 	return m, nil
 }
 
@@ -246,3 +312,220 @@ func (c I16) Unmarshal(b []byte) (int, interface{}) {
 func (c I16) GetMarshaledSize(b []byte) int {
 	return 2
 }
+
+// U8 converts uint8 to slice of 1 byte and back.
+type U8 struct{}
+
+// Marshal converts uint8 to slice of 1 byte.
+func (c U8) Marshal(d interface{}) []byte {
+	return []byte{d.(uint8)}
+}
+
+// Unmarshal converts slice of 1 byte to uint8.
+// It returns number bytes consumed and an uint8.
+func (c U8) Unmarshal(b []byte) (int, interface{}) {
+	return 1, b[0]
+}
+
+// GetMarshaledSize returns 1.
+func (c U8) GetMarshaledSize(b []byte) int {
+	return 1
+}
+
+// I8 converts int8 to slice of 1 byte and back.
+type I8 struct{}
+
+// Marshal converts int8 to slice of 1 byte.
+func (c I8) Marshal(d interface{}) []byte {
+	return []byte{byte(d.(int8))}
+}
+
+// Unmarshal converts slice of 1 byte to int8.
+// It returns number bytes consumed and an int8.
+func (c I8) Unmarshal(b []byte) (int, interface{}) {
+	return 1, int8(b[0])
+}
+
+// GetMarshaledSize returns 1.
+func (c I8) GetMarshaledSize(b []byte) int {
+	return 1
+}
+
+// F64 converts float64 to slice of 8 bytes and back.
+type F64 struct{}
+
+// Marshal converts float64 to slice of 8 bytes.
+func (c F64) Marshal(d interface{}) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, math.Float64bits(d.(float64)))
+	return b
+}
+
+// Unmarshal converts slice of 8 bytes to float64.
+// It returns number bytes consumed and a float64.
+func (c F64) Unmarshal(b []byte) (int, interface{}) {
+
+	size := int(8)
+	s := b[:size]
+
+	d := binary.LittleEndian.Uint64(s)
+	return size, math.Float64frombits(d)
+}
+
+// GetMarshaledSize returns 8.
+func (c F64) GetMarshaledSize(b []byte) int {
+	return 8
+}
+
+// F32 converts float32 to slice of 4 bytes and back.
+type F32 struct{}
+
+// Marshal converts float32 to slice of 4 bytes.
+func (c F32) Marshal(d interface{}) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, math.Float32bits(d.(float32)))
+	return b
+}
+
+// Unmarshal converts slice of 4 bytes to float32.
+// It returns number bytes consumed and a float32.
+func (c F32) Unmarshal(b []byte) (int, interface{}) {
+
+	size := int(4)
+	s := b[:size]
+
+	d := binary.LittleEndian.Uint32(s)
+	return size, math.Float32frombits(d)
+}
+
+// GetMarshaledSize returns 4.
+func (c F32) GetMarshaledSize(b []byte) int {
+	return 4
+}
+
+// Bool converts bool to slice of 1 byte and back.
+type Bool struct{}
+
+// Marshal converts bool to slice of 1 byte: 1 for true and 0 for false.
+func (c Bool) Marshal(d interface{}) []byte {
+	if d.(bool) {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// Unmarshal converts slice of 1 byte to bool.
+// It returns number bytes consumed and a bool.
+func (c Bool) Unmarshal(b []byte) (int, interface{}) {
+	return 1, b[0] != 0
+}
+
+// GetMarshaledSize returns 1.
+func (c Bool) GetMarshaledSize(b []byte) int {
+	return 1
+}
+
+// Bytes16 converts []byte to 2 bytes length followed by the bytes, and back.
+type Bytes16 struct{}
+
+// Marshal converts []byte to 2 bytes length followed by the bytes.
+func (c Bytes16) Marshal(d interface{}) []byte {
+	s := d.([]byte)
+	l := len(s)
+	rst := make([]byte, 2, 2+l)
+	rst[0] = byte(l >> 8)
+	rst[1] = byte(l)
+	return append(rst, s...)
+}
+
+// Unmarshal converts 2 bytes length followed by the bytes to []byte.
+// It returns number bytes consumed and a []byte.
+func (c Bytes16) Unmarshal(b []byte) (int, interface{}) {
+	l := int(b[0])<<8 + int(b[1])
+	s := make([]byte, l)
+	copy(s, b[2:2+l])
+	return 2 + l, s
+}
+
+// GetMarshaledSize returned size of marshaled data.
+func (c Bytes16) GetMarshaledSize(b []byte) int {
+	l := int(b[0])<<8 + int(b[1])
+	return 2 + l
+}
+
+// Named converts a value of a named type, such as `type Tier uint8`, with the
+// Marshaller of the builtin type of the same kind, and back.
+//
+// Type is the named type and Elt converts the builtin type.
+type Named struct {
+	Type reflect.Type
+	Elt  Marshaller
+}
+
+// Marshal converts a value of Type to the builtin type and marshals it.
+func (c Named) Marshal(d interface{}) []byte {
+	bt := builtinTypes[c.Type.Kind()]
+	return c.Elt.Marshal(reflect.ValueOf(d).Convert(bt).Interface())
+}
+
+// Unmarshal unmarshals a value of the builtin type and converts it to Type.
+// It returns number bytes consumed and a value of Type.
+func (c Named) Unmarshal(b []byte) (int, interface{}) {
+	n, v := c.Elt.Unmarshal(b)
+	return n, reflect.ValueOf(v).Convert(c.Type).Interface()
+}
+
+// GetMarshaledSize returned size of marshaled data.
+func (c Named) GetMarshaledSize(b []byte) int {
+	return c.Elt.GetMarshaledSize(b)
+}
+
+// Array converts an array, such as [4]uint16, to its elements marshaled one
+// after another, and back.
+//
+// Type is the array type and Elt converts its element.
+// Elements of a named type, such as `type ID uint32`, are converted to the
+// builtin type Elt accepts.
+type Array struct {
+	Type reflect.Type
+	Elt  Marshaller
+}
+
+// Marshal converts an array of Type to marshaled elements.
+func (c Array) Marshal(d interface{}) []byte {
+	v := reflect.ValueOf(d)
+
+	rst := []byte{}
+	for i := 0; i < v.Len(); i++ {
+		e := v.Index(i)
+		if t, ok := builtinTypes[e.Kind()]; ok {
+			e = e.Convert(t)
+		}
+		rst = append(rst, c.Elt.Marshal(e.Interface())...)
+	}
+	return rst
+}
+
+// Unmarshal converts marshaled elements to an array of Type.
+// It returns number bytes consumed and an array.
+func (c Array) Unmarshal(b []byte) (int, interface{}) {
+	v := reflect.New(c.Type).Elem()
+	eltType := c.Type.Elem()
+
+	n := 0
+	for i := 0; i < v.Len(); i++ {
+		l, e := c.Elt.Unmarshal(b[n:])
+		v.Index(i).Set(reflect.ValueOf(e).Convert(eltType))
+		n += l
+	}
+	return n, v.Interface()
+}
+
+// GetMarshaledSize returned size of marshaled data.
+func (c Array) GetMarshaledSize(b []byte) int {
+	n := 0
+	for i := 0; i < c.Type.Len(); i++ {
+		n += c.Elt.GetMarshaledSize(b[n:])
+	}
+	return n
+}
@@ -1,6 +1,8 @@
 package marshal
 
 import (
+	"math"
+	"reflect"
 	"testing"
 )
 
@@ -282,6 +284,82 @@ func TestI16(t *testing.T) {
 	}
 }
 
+func TestMarshallers(t *testing.T) {
+
+	type id uint16
+
+	cases := []struct {
+		m        Marshaller
+		input    interface{}
+		want     string
+		wantsize int
+	}{
+		{U8{}, uint8(0), "\x00", 1},
+		{U8{}, uint8(0xfe), "\xfe", 1},
+		{I8{}, int8(1), "\x01", 1},
+		{I8{}, int8(-1), "\xff", 1},
+		{F32{}, float32(0), "\x00\x00\x00\x00", 4},
+		{F32{}, float32(1.5), "\x00\x00\xc0\x3f", 4},
+		{F32{}, float32(math.MaxFloat32), "\xff\xff\x7f\x7f", 4},
+		{F64{}, float64(-2), "\x00\x00\x00\x00\x00\x00\x00\xc0", 8},
+		{F64{}, math.SmallestNonzeroFloat64, "\x01\x00\x00\x00\x00\x00\x00\x00", 8},
+		{Bool{}, false, "\x00", 1},
+		{Bool{}, true, "\x01", 1},
+		{Bytes16{}, []byte{}, "\x00\x00", 2},
+		{Bytes16{}, []byte("abc"), "\x00\x03abc", 5},
+		{
+			Array{Type: reflect.TypeOf([3]uint16{}), Elt: U16{}},
+			[3]uint16{1, 2, 0x1234},
+			"\x01\x00\x02\x00\x34\x12", 6,
+		},
+		{
+			Array{Type: reflect.TypeOf([2]id{}), Elt: U16{}},
+			[2]id{1, 2},
+			"\x01\x00\x02\x00", 4,
+		},
+		{
+			Array{Type: reflect.TypeOf([2]string{}), Elt: String16{}},
+			[2]string{"a", "bc"},
+			"\x00\x01a\x00\x02bc", 7,
+		},
+		{
+			Array{Type: reflect.TypeOf([0]bool{}), Elt: Bool{}},
+			[0]bool{},
+			"", 0,
+		},
+	}
+
+	for i, c := range cases {
+		rst := c.m.Marshal(c.input)
+		if string(rst) != c.want {
+			t.Fatalf("%d-th: input: %v; want: %v; actual: %v",
+				i+1, c.input, []byte(c.want), rst)
+		}
+
+		n := c.m.GetMarshaledSize(rst)
+		if c.wantsize != n {
+			t.Fatalf("%d-th: input: %v; wantsize: %v; actual: %v",
+				i+1, c.input, c.wantsize, n)
+		}
+
+		n, v := c.m.Unmarshal(rst)
+		if !reflect.DeepEqual(c.input, v) {
+			t.Fatalf("%d-th: unmarshal: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.input, v)
+		}
+		if c.wantsize != n {
+			t.Fatalf("%d-th: unmarshalled size: input: %v; want: %v; actual: %v",
+				i+1, c.input, c.wantsize, n)
+		}
+	}
+}
+
+type testTier uint8
+type testName string
+type testBlob []byte
+type testByte uint8
+type testPair [2]testTier
+
 func TestGetMarshaller(t *testing.T) {
 
 	cases := []struct {
@@ -304,6 +382,45 @@ func TestGetMarshaller(t *testing.T) {
 			U64{},
 			nil,
 		},
+		{uint8(0), U8{}, nil},
+		{int8(0), I8{}, nil},
+		{int16(0), I16{}, nil},
+		{int32(0), I32{}, nil},
+		{int64(0), I64{}, nil},
+		{float32(0), F32{}, nil},
+		{float64(0), F64{}, nil},
+		{false, Bool{}, nil},
+		{"", String16{}, nil},
+		{[]byte{}, Bytes16{}, nil},
+		{
+			[4]uint32{},
+			Array{Type: reflect.TypeOf([4]uint32{}), Elt: U32{}},
+			nil,
+		},
+		{
+			[2][3]int8{},
+			Array{
+				Type: reflect.TypeOf([2][3]int8{}),
+				Elt:  Array{Type: reflect.TypeOf([3]int8{}), Elt: I8{}},
+			},
+			nil,
+		},
+		{testTier(0), Named{Type: reflect.TypeOf(testTier(0)), Elt: U8{}}, nil},
+		{testName(""), Named{Type: reflect.TypeOf(testName("")), Elt: String16{}}, nil},
+		{testBlob{}, Named{Type: reflect.TypeOf(testBlob{}), Elt: Bytes16{}}, nil},
+		{
+			testPair{},
+			Array{
+				Type: reflect.TypeOf(testPair{}),
+				Elt:  Named{Type: reflect.TypeOf(testTier(0)), Elt: U8{}},
+			},
+			nil,
+		},
+		{[]testByte{}, nil, ErrUnknownEltType},
+		{int(0), nil, ErrUnknownEltType},
+		{uint(0), nil, ErrUnknownEltType},
+		{[2]int{}, nil, ErrUnknownEltType},
+		{struct{}{}, nil, ErrUnknownEltType},
 		{
 			[]int{},
 			nil,
@@ -329,6 +446,42 @@ func TestGetMarshaller(t *testing.T) {
 	}
 }
 
+func TestNamed(t *testing.T) {
+
+	cases := []struct {
+		input interface{}
+		want  string
+	}{
+		{testTier(3), "\x03"},
+		{testName("ab"), "\x00\x02ab"},
+		{testBlob{1, 2}, "\x00\x02\x01\x02"},
+		{testPair{1, 2}, "\x01\x02"},
+	}
+
+	for i, c := range cases {
+		m, err := GetMarshaller(c.input)
+		if err != nil {
+			t.Fatalf("%d-th: expect no error but: %s", i+1, err)
+		}
+
+		rst := m.Marshal(c.input)
+		if string(rst) != c.want {
+			t.Fatalf("%d-th: input: %v; want: %v; actual: %v",
+				i+1, c.input, []byte(c.want), rst)
+		}
+
+		if n := m.GetMarshaledSize(rst); n != len(c.want) {
+			t.Fatalf("%d-th: expect size %d but: %d", i+1, len(c.want), n)
+		}
+
+		n, v := m.Unmarshal(rst)
+		if n != len(c.want) || !reflect.DeepEqual(c.input, v) {
+			t.Fatalf("%d-th: unmarshal: want: %#v; actual: %d %#v",
+				i+1, c.input, n, v)
+		}
+	}
+}
+
 func TestGetSliceEltMarshaller(t *testing.T) {
 
 	cases := []struct {
@@ -351,6 +504,17 @@ func TestGetSliceEltMarshaller(t *testing.T) {
 			U64{},
 			nil,
 		},
+		{[]int8{}, I8{}, nil},
+		{[]float64{}, F64{}, nil},
+		{[]bool{}, Bool{}, nil},
+		{[]string{}, String16{}, nil},
+		{[][]byte{}, Bytes16{}, nil},
+		{
+			[][2]uint16{},
+			Array{Type: reflect.TypeOf([2]uint16{}), Elt: U16{}},
+			nil,
+		},
+		{[][]uint16{}, nil, ErrUnknownEltType},
 		{
 			[]int{},
 			nil,
@@ -0,0 +1,323 @@
+package marshal
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotStruct indicates it expects a struct or a pointer to struct but not.
+	ErrNotStruct = errors.New("it is not a struct")
+	// ErrStructTag indicates an invalid `slim` tag of a struct field.
+	ErrStructTag = errors.New("invalid slim tag")
+	// ErrUnexportedField indicates a struct field is unexported and not
+	// skipped by tag `slim:"-"`.
+	ErrUnexportedField = errors.New("field is unexported")
+	// ErrFieldSize indicates a field value does not fit in its marshaled size.
+	ErrFieldSize = errors.New("field value does not fit in its size")
+)
+
+// structField describes how to marshal a field of a struct.
+type structField struct {
+	index int
+	kind  reflect.Kind
+	size  int
+}
+
+// StructMarshaller converts a flat struct of fixed-size fields to its fields
+// marshaled one after another, and back.
+// It is created by Struct.
+type StructMarshaller struct {
+	typ    reflect.Type
+	fields []structField
+	size   int
+}
+
+// Struct creates a Marshaller for the struct type of `prototype`, which is a
+// struct or a pointer to struct.
+//
+// Supported field types are uint8 to uint64, int8 to int64, float32, float64,
+// bool, [N]byte, string and []byte.
+// Integers are stored in little-endian.
+//
+// Tag `slim:"size=N"` specifies the marshaled size of a field:
+// an integer is stored in the lowest N bytes, and a string or []byte field is
+// stored as exactly N bytes, thus a string or []byte field requires it.
+// A string or []byte shorter than N is padded with trailing zeros, and trailing
+// zeros are trimmed when unmarshaling.
+// Thus a value that ends with zero bytes does not round-trip.
+// Tag `slim:"-"` skips a field.
+// E.g.:
+//
+//	type Pos struct {
+//		Shard  string `slim:"size=4"`
+//		Offset uint64 `slim:"size=5"`
+//		Hot    bool
+//	}
+//	m, err := marshal.Struct(Pos{})
+//
+// The marshaled size is computed once, and is 10 for Pos.
+//
+// Marshal accepts a struct or a pointer to struct, and panics with
+// ErrFieldSize if a field does not fit in its size, e.g., a string longer than
+// N.
+// Unmarshal returns a struct.
+func Struct(prototype interface{}) (*StructMarshaller, error) {
+
+	t := reflect.TypeOf(prototype)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+
+	m := &StructMarshaller{typ: t}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		tag := f.Tag.Get("slim")
+		if tag == "-" {
+			continue
+		}
+
+		if f.PkgPath != "" {
+			return nil, ErrUnexportedField
+		}
+
+		size, err := fieldSize(f.Type, tag)
+		if err != nil {
+			return nil, err
+		}
+
+		m.fields = append(m.fields, structField{
+			index: i,
+			kind:  f.Type.Kind(),
+			size:  size,
+		})
+		m.size += size
+	}
+
+	return m, nil
+}
+
+// fieldSize returns the marshaled size of a field of type `t` with tag `tag`.
+func fieldSize(t reflect.Type, tag string) (int, error) {
+
+	size := -1
+	if tag != "" {
+		for _, opt := range strings.Split(tag, ",") {
+			if !strings.HasPrefix(opt, "size=") {
+				return 0, ErrStructTag
+			}
+
+			n, err := strconv.Atoi(strings.TrimPrefix(opt, "size="))
+			if err != nil || n < 0 {
+				return 0, ErrStructTag
+			}
+			size = n
+		}
+	}
+
+	natural := 0
+
+	switch t.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		natural = int(t.Size())
+		if size == -1 {
+			return natural, nil
+		}
+		if size < 1 || size > natural {
+			return 0, ErrStructTag
+		}
+		return size, nil
+
+	case reflect.String:
+		if size == -1 {
+			return 0, ErrStructTag
+		}
+		return size, nil
+
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return 0, ErrUnknownEltType
+		}
+		if size == -1 {
+			return 0, ErrStructTag
+		}
+		return size, nil
+
+	case reflect.Float32, reflect.Float64:
+		natural = int(t.Size())
+	case reflect.Bool:
+		natural = 1
+	case reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return 0, ErrUnknownEltType
+		}
+		natural = t.Len()
+	default:
+		return 0, ErrUnknownEltType
+	}
+
+	if size != -1 && size != natural {
+		return 0, ErrStructTag
+	}
+	return natural, nil
+}
+
+// Marshal converts a struct or a pointer to struct to slice of bytes.
+func (m *StructMarshaller) Marshal(d interface{}) []byte {
+
+	v := reflect.ValueOf(d)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	b := make([]byte, m.size)
+	off := 0
+
+	for _, f := range m.fields {
+		fv := v.Field(f.index)
+		buf := b[off : off+f.size]
+
+		switch f.kind {
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u := fv.Uint()
+			if f.size < 8 && u>>uint(8*f.size) != 0 {
+				panic(ErrFieldSize)
+			}
+			putUint(buf, u)
+
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i := fv.Int()
+			if f.size < 8 {
+				bits := uint(8 * f.size)
+				if i < -1<<(bits-1) || i >= 1<<(bits-1) {
+					panic(ErrFieldSize)
+				}
+			}
+			putUint(buf, uint64(i))
+
+		case reflect.Float32:
+			binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(fv.Float())))
+
+		case reflect.Float64:
+			binary.LittleEndian.PutUint64(buf, math.Float64bits(fv.Float()))
+
+		case reflect.Bool:
+			if fv.Bool() {
+				buf[0] = 1
+			}
+
+		case reflect.String:
+			s := fv.String()
+			if len(s) > f.size {
+				panic(ErrFieldSize)
+			}
+			copy(buf, s)
+
+		case reflect.Slice:
+			s := fv.Bytes()
+			if len(s) > f.size {
+				panic(ErrFieldSize)
+			}
+			copy(buf, s)
+
+		case reflect.Array:
+			for i := range buf {
+				buf[i] = byte(fv.Index(i).Uint())
+			}
+		}
+
+		off += f.size
+	}
+
+	return b
+}
+
+// Unmarshal converts slice of bytes to a struct.
+// It returns number bytes consumed and a struct.
+func (m *StructMarshaller) Unmarshal(b []byte) (int, interface{}) {
+
+	v := reflect.New(m.typ).Elem()
+	off := 0
+
+	for _, f := range m.fields {
+		fv := v.Field(f.index)
+		buf := b[off : off+f.size]
+
+		switch f.kind {
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fv.SetUint(getUint(buf))
+
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			// sign-extend the highest stored bit
+			shift := uint(64 - 8*f.size)
+			fv.SetInt(int64(getUint(buf)<<shift) >> shift)
+
+		case reflect.Float32:
+			fv.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(buf))))
+
+		case reflect.Float64:
+			fv.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(buf)))
+
+		case reflect.Bool:
+			fv.SetBool(buf[0] != 0)
+
+		case reflect.String:
+			fv.SetString(string(trimZeros(buf)))
+
+		case reflect.Slice:
+			raw := trimZeros(buf)
+			s := make([]byte, len(raw))
+			copy(s, raw)
+			fv.SetBytes(s)
+
+		case reflect.Array:
+			for i, c := range buf {
+				fv.Index(i).SetUint(uint64(c))
+			}
+		}
+
+		off += f.size
+	}
+
+	return m.size, v.Interface()
+}
+
+// GetMarshaledSize returns the size computed by Struct.
+func (m *StructMarshaller) GetMarshaledSize(b []byte) int {
+	return m.size
+}
+
+// trimZeros returns `b` without trailing zero bytes.
+func trimZeros(b []byte) []byte {
+	n := len(b)
+	for n > 0 && b[n-1] == 0 {
+		n--
+	}
+	return b[:n]
+}
+
+// putUint stores the lowest len(b) bytes of `u` in little-endian.
+func putUint(b []byte, u uint64) {
+	for i := range b {
+		b[i] = byte(u >> uint(8*i))
+	}
+}
+
+// getUint loads a uint64 from bytes in little-endian.
+func getUint(b []byte) uint64 {
+	var u uint64
+	for i, c := range b {
+		u |= uint64(c) << uint(8*i)
+	}
+	return u
+}
@@ -0,0 +1,208 @@
+package marshal
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+type testPos struct {
+	Shard  string `slim:"size=4"`
+	Offset uint64 `slim:"size=5"`
+	Delta  int32  `slim:"size=3"`
+	Hot    bool
+	Weight float32
+	Hash   [3]byte
+	Raw    []byte `slim:"size=2"`
+	Memo   string `slim:"-"`
+	n      int    `slim:"-"`
+}
+
+func TestStruct(t *testing.T) {
+
+	m, err := Struct(testPos{})
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	mp, err := Struct(&testPos{})
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+	if !reflect.DeepEqual(m, mp) {
+		t.Fatalf("expect the same Marshaller for pointer prototype")
+	}
+
+	cases := []struct {
+		input testPos
+		want  string
+	}{
+		{
+			testPos{Shard: "s001", Raw: []byte{}},
+			"s001" + "\x00\x00\x00\x00\x00" + "\x00\x00\x00" + "\x00" +
+				"\x00\x00\x00\x00" + "\x00\x00\x00" + "\x00\x00",
+		},
+		{
+			testPos{
+				Shard:  "abcd",
+				Offset: 0xff00000102,
+				Delta:  -2,
+				Hot:    true,
+				Weight: 1.5,
+				Hash:   [3]byte{1, 2, 3},
+				Raw:    []byte{4, 5},
+			},
+			"abcd" + "\x02\x01\x00\x00\xff" + "\xfe\xff\xff" + "\x01" +
+				"\x00\x00\xc0\x3f" + "\x01\x02\x03" + "\x04\x05",
+		},
+		{
+			testPos{Shard: "zzzz", Delta: 1<<23 - 1, Raw: []byte{6, 7}},
+			"zzzz" + "\x00\x00\x00\x00\x00" + "\xff\xff\x7f" + "\x00" +
+				"\x00\x00\x00\x00" + "\x00\x00\x00" + "\x06\x07",
+		},
+		{
+			testPos{Shard: "zzzz", Delta: -1 << 23, Raw: []byte{6, 7}},
+			"zzzz" + "\x00\x00\x00\x00\x00" + "\x00\x00\x80" + "\x00" +
+				"\x00\x00\x00\x00" + "\x00\x00\x00" + "\x06\x07",
+		},
+		{
+			// short string and []byte are padded with zeros
+			testPos{Shard: "ab", Raw: []byte{9}},
+			"ab\x00\x00" + "\x00\x00\x00\x00\x00" + "\x00\x00\x00" + "\x00" +
+				"\x00\x00\x00\x00" + "\x00\x00\x00" + "\x09\x00",
+		},
+	}
+
+	for i, c := range cases {
+		for _, input := range []interface{}{c.input, &c.input} {
+
+			rst := m.Marshal(input)
+			if string(rst) != c.want {
+				t.Fatalf("%d-th: input: %v; want: %v; actual: %v",
+					i+1, c.input, []byte(c.want), rst)
+			}
+
+			n := m.GetMarshaledSize(rst)
+			if n != 22 {
+				t.Fatalf("%d-th: expect size 22 but: %d", i+1, n)
+			}
+
+			n, v := m.Unmarshal(rst)
+			if n != 22 {
+				t.Fatalf("%d-th: expect unmarshalled size 22 but: %d", i+1, n)
+			}
+			if !reflect.DeepEqual(c.input, v) {
+				t.Fatalf("%d-th: unmarshal: want: %v; actual: %v",
+					i+1, c.input, v)
+			}
+		}
+	}
+
+	// skipped fields are not marshaled
+	_, v := m.Unmarshal(m.Marshal(testPos{Shard: "abcd", Raw: []byte{1, 2}, Memo: "x", n: 3}))
+	if v.(testPos).Memo != "" || v.(testPos).n != 0 {
+		t.Fatalf("expect skipped fields to be zero but: %v", v)
+	}
+}
+
+func TestStructFloat64(t *testing.T) {
+
+	type pt struct {
+		X, Y float64
+		Z    int64
+	}
+
+	m, err := Struct(pt{})
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	input := pt{-0.5, math.MaxFloat64, math.MinInt64}
+	rst := m.Marshal(input)
+	if len(rst) != 24 || m.GetMarshaledSize(nil) != 24 {
+		t.Fatalf("expect size 24 but: %d %d", len(rst), m.GetMarshaledSize(nil))
+	}
+
+	n, v := m.Unmarshal(rst)
+	if n != 24 || v.(pt) != input {
+		t.Fatalf("expect: %v but: %d %v", input, n, v)
+	}
+}
+
+func TestStructError(t *testing.T) {
+
+	cases := []struct {
+		input   interface{}
+		wanterr error
+	}{
+		{nil, ErrNotStruct},
+		{1, ErrNotStruct},
+		{[]testPos{}, ErrNotStruct},
+		{struct{ A int }{}, ErrUnknownEltType},
+		{struct{ A []uint16 }{}, ErrUnknownEltType},
+		{struct{ A [2]uint16 }{}, ErrUnknownEltType},
+		{struct{ A struct{ B uint8 } }{}, ErrUnknownEltType},
+		{struct{ a uint8 }{}, ErrUnexportedField},
+		{struct{ A string }{}, ErrStructTag},
+		{struct{ A []byte }{}, ErrStructTag},
+		{struct {
+			A uint16 `slim:"size=3"`
+		}{}, ErrStructTag},
+		{struct {
+			A uint16 `slim:"size=0"`
+		}{}, ErrStructTag},
+		{struct {
+			A uint16 `slim:"size=x"`
+		}{}, ErrStructTag},
+		{struct {
+			A uint16 `slim:"foo"`
+		}{}, ErrStructTag},
+		{struct {
+			A float32 `slim:"size=2"`
+		}{}, ErrStructTag},
+		{struct {
+			A [2]byte `slim:"size=3"`
+		}{}, ErrStructTag},
+	}
+
+	for i, c := range cases {
+		_, err := Struct(c.input)
+		if err != c.wanterr {
+			t.Fatalf("%d-th: input: %#v; wanterr: %v; actual: %v",
+				i+1, c.input, c.wanterr, err)
+		}
+	}
+}
+
+func TestStructFieldSize(t *testing.T) {
+
+	type narrow struct {
+		U uint32 `slim:"size=1"`
+		I int16  `slim:"size=1"`
+		S string `slim:"size=2"`
+	}
+
+	m, err := Struct(narrow{})
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	cases := []narrow{
+		{U: 256, S: "ab"},
+		{I: 128, S: "ab"},
+		{I: -129, S: "ab"},
+		{S: "abc"},
+	}
+
+	for i, c := range cases {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrFieldSize {
+					t.Fatalf("%d-th: input: %v; expect panic ErrFieldSize but: %v",
+						i+1, c, r)
+				}
+			}()
+			m.Marshal(c)
+		}()
+	}
+}
@@ -20,13 +20,15 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type Array32Storage struct {
 	// compatiblity gurantee:
-	//     reserved field number: 1, 2, 3, 4
-	//     reserved field name: Cnt, Bitmaps, Offsets, Elts
+	//     reserved field number: 1, 2, 3, 4, 5, 6
+	//     reserved field name: Cnt, Bitmaps, Offsets, Elts, EltOffsets, ConverterName
 	//
 	Cnt                  uint32   `protobuf:"varint,1,opt,name=Cnt,proto3" json:"Cnt,omitempty"`
 	Bitmaps              []uint64 `protobuf:"varint,2,rep,packed,name=Bitmaps,proto3" json:"Bitmaps,omitempty"`
 	Offsets              []uint32 `protobuf:"varint,3,rep,packed,name=Offsets,proto3" json:"Offsets,omitempty"`
 	Elts                 []byte   `protobuf:"bytes,4,opt,name=Elts,proto3" json:"Elts,omitempty"`
+	EltOffsets           []uint32 `protobuf:"varint,5,rep,packed,name=EltOffsets,proto3" json:"EltOffsets,omitempty"`
+	ConverterName        string   `protobuf:"bytes,6,opt,name=ConverterName,proto3" json:"ConverterName,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -36,7 +38,7 @@ func (m *Array32Storage) Reset()         { *m = Array32Storage{} }
 func (m *Array32Storage) String() string { return proto.CompactTextString(m) }
 func (*Array32Storage) ProtoMessage()    {}
 func (*Array32Storage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_array_8f197c61c8740174, []int{0}
+	return fileDescriptor_array_eb5671b0d1224a13, []int{0}
 }
 func (m *Array32Storage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Array32Storage.Unmarshal(m, b)
@@ -84,21 +86,37 @@ func (m *Array32Storage) GetElts() []byte {
 	return nil
 }
 
+func (m *Array32Storage) GetEltOffsets() []uint32 {
+	if m != nil {
+		return m.EltOffsets
+	}
+	return nil
+}
+
+func (m *Array32Storage) GetConverterName() string {
+	if m != nil {
+		return m.ConverterName
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Array32Storage)(nil), "Array32Storage")
 }
 
-func init() { proto.RegisterFile("array.proto", fileDescriptor_array_8f197c61c8740174) }
-
-var fileDescriptor_array_8f197c61c8740174 = []byte{
-	// 138 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4e, 0x2c, 0x2a, 0x4a,
-	0xac, 0xd4, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0xca, 0xe2, 0xe2, 0x73, 0x04, 0x71, 0x8d, 0x8d,
-	0x82, 0x4b, 0xf2, 0x8b, 0x12, 0xd3, 0x53, 0x85, 0x04, 0xb8, 0x98, 0x9d, 0xf3, 0x4a, 0x24, 0x18,
-	0x15, 0x18, 0x35, 0x78, 0x83, 0x40, 0x4c, 0x21, 0x09, 0x2e, 0x76, 0xa7, 0xcc, 0x92, 0xdc, 0xc4,
-	0x82, 0x62, 0x09, 0x26, 0x05, 0x66, 0x0d, 0x96, 0x20, 0x18, 0x17, 0x24, 0xe3, 0x9f, 0x96, 0x56,
-	0x9c, 0x5a, 0x52, 0x2c, 0xc1, 0xac, 0xc0, 0xac, 0xc1, 0x1b, 0x04, 0xe3, 0x0a, 0x09, 0x71, 0xb1,
-	0xb8, 0xe6, 0x94, 0x14, 0x4b, 0xb0, 0x28, 0x30, 0x6a, 0xf0, 0x04, 0x81, 0xd9, 0x4e, 0xdc, 0x51,
-	0x9c, 0x60, 0x4b, 0x4b, 0x2a, 0x0b, 0x52, 0x93, 0xd8, 0xc0, 0x4c, 0x63, 0x40, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0x41, 0xac, 0x2c, 0xe0, 0x8e, 0x00, 0x00, 0x00,
+func init() { proto.RegisterFile("array.proto", fileDescriptor_array_eb5671b0d1224a13) }
+
+var fileDescriptor_array_eb5671b0d1224a13 = []byte{
+	// 169 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xe2, 0x4e, 0x2c, 0x2a, 0x4a,
+	0xac, 0xd4, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0xda, 0xc0, 0xc8, 0xc5, 0xe7, 0x08, 0xe2, 0x1b,
+	0x1b, 0x05, 0x97, 0xe4, 0x17, 0x25, 0xa6, 0xa7, 0x0a, 0x09, 0x70, 0x31, 0x3b, 0xe7, 0x95, 0x48,
+	0x30, 0x2a, 0x30, 0x6a, 0xf0, 0x06, 0x81, 0x98, 0x42, 0x12, 0x5c, 0xec, 0x4e, 0x99, 0x25, 0xb9,
+	0x89, 0x05, 0xc5, 0x12, 0x4c, 0x0a, 0xcc, 0x1a, 0x2c, 0x41, 0x30, 0x2e, 0x48, 0xc6, 0x3f, 0x2d,
+	0xad, 0x38, 0xb5, 0xa4, 0x58, 0x82, 0x19, 0x28, 0xc3, 0x1b, 0x04, 0xe3, 0x0a, 0x09, 0x71, 0xb1,
+	0xb8, 0xe6, 0x00, 0x85, 0x59, 0x80, 0xc6, 0xf0, 0x04, 0x81, 0xd9, 0x42, 0x72, 0x5c, 0x5c, 0x40,
+	0x1a, 0xa6, 0x81, 0x15, 0xac, 0x01, 0x49, 0x44, 0x48, 0x85, 0x8b, 0xd7, 0x39, 0x3f, 0xaf, 0x2c,
+	0xb5, 0xa8, 0x24, 0xb5, 0xc8, 0x2f, 0x31, 0x37, 0x55, 0x82, 0x0d, 0xa8, 0x99, 0x33, 0x08, 0x55,
+	0xd0, 0x89, 0x3b, 0x8a, 0x13, 0xec, 0xf6, 0x92, 0xca, 0x82, 0xd4, 0x24, 0x36, 0x30, 0xd3, 0x18,
+	0x00, 0x17, 0xd9, 0x00, 0xf1, 0xd5, 0x00, 0x00, 0x00,
 }
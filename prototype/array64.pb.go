@@ -0,0 +1,124 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: array64.proto
+
+package prototype
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Array64Storage struct {
+	// compatiblity gurantee:
+	//     reserved field number: 1, 2, 3, 4, 5, 7
+	//     reserved field name: Cnt, Bitmaps, Offsets, Elts, EltOffsets, Words
+	//
+	// Field 1 to 5 are the same as Array32Storage.
+	// Field 6 is kept for ConverterName of Array32Storage.
+	//
+	Cnt                  uint32   `protobuf:"varint,1,opt,name=Cnt,proto3" json:"Cnt,omitempty"`
+	Bitmaps              []uint64 `protobuf:"varint,2,rep,packed,name=Bitmaps,proto3" json:"Bitmaps,omitempty"`
+	Offsets              []uint32 `protobuf:"varint,3,rep,packed,name=Offsets,proto3" json:"Offsets,omitempty"`
+	Elts                 []byte   `protobuf:"bytes,4,opt,name=Elts,proto3" json:"Elts,omitempty"`
+	EltOffsets           []uint32 `protobuf:"varint,5,rep,packed,name=EltOffsets,proto3" json:"EltOffsets,omitempty"`
+	Words                []uint64 `protobuf:"varint,7,rep,packed,name=Words,proto3" json:"Words,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Array64Storage) Reset()         { *m = Array64Storage{} }
+func (m *Array64Storage) String() string { return proto.CompactTextString(m) }
+func (*Array64Storage) ProtoMessage()    {}
+func (*Array64Storage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_array64_3d1fb5ba977606e4, []int{0}
+}
+func (m *Array64Storage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Array64Storage.Unmarshal(m, b)
+}
+func (m *Array64Storage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Array64Storage.Marshal(b, m, deterministic)
+}
+func (dst *Array64Storage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Array64Storage.Merge(dst, src)
+}
+func (m *Array64Storage) XXX_Size() int {
+	return xxx_messageInfo_Array64Storage.Size(m)
+}
+func (m *Array64Storage) XXX_DiscardUnknown() {
+	xxx_messageInfo_Array64Storage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Array64Storage proto.InternalMessageInfo
+
+func (m *Array64Storage) GetCnt() uint32 {
+	if m != nil {
+		return m.Cnt
+	}
+	return 0
+}
+
+func (m *Array64Storage) GetBitmaps() []uint64 {
+	if m != nil {
+		return m.Bitmaps
+	}
+	return nil
+}
+
+func (m *Array64Storage) GetOffsets() []uint32 {
+	if m != nil {
+		return m.Offsets
+	}
+	return nil
+}
+
+func (m *Array64Storage) GetElts() []byte {
+	if m != nil {
+		return m.Elts
+	}
+	return nil
+}
+
+func (m *Array64Storage) GetEltOffsets() []uint32 {
+	if m != nil {
+		return m.EltOffsets
+	}
+	return nil
+}
+
+func (m *Array64Storage) GetWords() []uint64 {
+	if m != nil {
+		return m.Words
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Array64Storage)(nil), "Array64Storage")
+}
+
+func init() { proto.RegisterFile("array64.proto", fileDescriptor_array64_3d1fb5ba977606e4) }
+
+var fileDescriptor_array64_3d1fb5ba977606e4 = []byte{
+	// 159 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xe2, 0x4d, 0x2c, 0x2a, 0x4a,
+	0xac, 0x34, 0x33, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0x5a, 0xc0, 0xc8, 0xc5, 0xe7, 0x08,
+	0x11, 0x09, 0x2e, 0xc9, 0x2f, 0x4a, 0x4c, 0x4f, 0x15, 0x12, 0xe0, 0x62, 0x76, 0xce, 0x2b, 0x91,
+	0x60, 0x54, 0x60, 0xd4, 0xe0, 0x0d, 0x02, 0x31, 0x85, 0x24, 0xb8, 0xd8, 0x9d, 0x32, 0x4b, 0x72,
+	0x13, 0x0b, 0x8a, 0x25, 0x98, 0x14, 0x98, 0x35, 0x58, 0x82, 0x60, 0x5c, 0x90, 0x8c, 0x7f, 0x5a,
+	0x5a, 0x71, 0x6a, 0x49, 0xb1, 0x04, 0x33, 0x50, 0x86, 0x37, 0x08, 0xc6, 0x15, 0x12, 0xe2, 0x62,
+	0x71, 0xcd, 0x01, 0x0a, 0xb3, 0x00, 0x8d, 0xe1, 0x09, 0x02, 0xb3, 0x85, 0xe4, 0xb8, 0xb8, 0x80,
+	0x34, 0x4c, 0x03, 0x2b, 0x58, 0x03, 0x92, 0x88, 0x90, 0x08, 0x17, 0x6b, 0x78, 0x7e, 0x51, 0x4a,
+	0xb1, 0x04, 0x3b, 0xd8, 0x16, 0x08, 0xc7, 0x89, 0x3b, 0x8a, 0x13, 0xec, 0xd6, 0x92, 0xca, 0x82,
+	0xd4, 0x24, 0x36, 0x30, 0xd3, 0x18, 0x00, 0xa4, 0x28, 0x77, 0x34, 0xc7, 0x00, 0x00, 0x00,
+}
@@ -0,0 +1,121 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: bitvector.proto
+
+package prototype
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type BitVectorStorage struct {
+	// compatiblity gurantee:
+	//     reserved field number: 1, 2, 3, 4, 5, 6
+	//     reserved field name: N, Ones, Words, Ranks, Select1Hints, Select0Hints
+	//
+	N                    uint64   `protobuf:"varint,1,opt,name=N,proto3" json:"N,omitempty"`
+	Ones                 uint64   `protobuf:"varint,2,opt,name=Ones,proto3" json:"Ones,omitempty"`
+	Words                []uint64 `protobuf:"varint,3,rep,packed,name=Words,proto3" json:"Words,omitempty"`
+	Ranks                []uint64 `protobuf:"varint,4,rep,packed,name=Ranks,proto3" json:"Ranks,omitempty"`
+	Select1Hints         []uint32 `protobuf:"varint,5,rep,packed,name=Select1Hints,proto3" json:"Select1Hints,omitempty"`
+	Select0Hints         []uint32 `protobuf:"varint,6,rep,packed,name=Select0Hints,proto3" json:"Select0Hints,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BitVectorStorage) Reset()         { *m = BitVectorStorage{} }
+func (m *BitVectorStorage) String() string { return proto.CompactTextString(m) }
+func (*BitVectorStorage) ProtoMessage()    {}
+func (*BitVectorStorage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_bitvector_e96ec00f6946d4e0, []int{0}
+}
+func (m *BitVectorStorage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BitVectorStorage.Unmarshal(m, b)
+}
+func (m *BitVectorStorage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BitVectorStorage.Marshal(b, m, deterministic)
+}
+func (dst *BitVectorStorage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BitVectorStorage.Merge(dst, src)
+}
+func (m *BitVectorStorage) XXX_Size() int {
+	return xxx_messageInfo_BitVectorStorage.Size(m)
+}
+func (m *BitVectorStorage) XXX_DiscardUnknown() {
+	xxx_messageInfo_BitVectorStorage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BitVectorStorage proto.InternalMessageInfo
+
+func (m *BitVectorStorage) GetN() uint64 {
+	if m != nil {
+		return m.N
+	}
+	return 0
+}
+
+func (m *BitVectorStorage) GetOnes() uint64 {
+	if m != nil {
+		return m.Ones
+	}
+	return 0
+}
+
+func (m *BitVectorStorage) GetWords() []uint64 {
+	if m != nil {
+		return m.Words
+	}
+	return nil
+}
+
+func (m *BitVectorStorage) GetRanks() []uint64 {
+	if m != nil {
+		return m.Ranks
+	}
+	return nil
+}
+
+func (m *BitVectorStorage) GetSelect1Hints() []uint32 {
+	if m != nil {
+		return m.Select1Hints
+	}
+	return nil
+}
+
+func (m *BitVectorStorage) GetSelect0Hints() []uint32 {
+	if m != nil {
+		return m.Select0Hints
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*BitVectorStorage)(nil), "BitVectorStorage")
+}
+
+func init() { proto.RegisterFile("bitvector.proto", fileDescriptor_bitvector_e96ec00f6946d4e0) }
+
+var fileDescriptor_bitvector_e96ec00f6946d4e0 = []byte{
+	// 158 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xe2, 0x4f, 0xca, 0x2c, 0x29,
+	0x4b, 0x4d, 0x2e, 0xc9, 0x2f, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0x5a, 0xc1, 0xc8, 0x25,
+	0xe0, 0x94, 0x59, 0x12, 0x06, 0x16, 0x0b, 0x06, 0xe2, 0xc4, 0xf4, 0x54, 0x21, 0x1e, 0x2e, 0x46,
+	0x3f, 0x09, 0x46, 0x05, 0x46, 0x0d, 0x96, 0x20, 0x46, 0x3f, 0x21, 0x21, 0x2e, 0x16, 0xff, 0xbc,
+	0xd4, 0x62, 0x09, 0x26, 0xb0, 0x00, 0x98, 0x2d, 0x24, 0xc2, 0xc5, 0x1a, 0x9e, 0x5f, 0x94, 0x52,
+	0x2c, 0xc1, 0xac, 0xc0, 0x0c, 0x14, 0x84, 0x70, 0x40, 0xa2, 0x41, 0x89, 0x79, 0xd9, 0xc5, 0x12,
+	0x2c, 0x10, 0x51, 0x30, 0x47, 0x48, 0x89, 0x8b, 0x27, 0x38, 0x35, 0x07, 0x68, 0x81, 0xa1, 0x47,
+	0x66, 0x5e, 0x49, 0xb1, 0x04, 0x2b, 0x50, 0x92, 0x37, 0x08, 0x45, 0x0c, 0xa1, 0xc6, 0x00, 0xa2,
+	0x86, 0x0d, 0x59, 0x0d, 0x44, 0xcc, 0x89, 0x3b, 0x8a, 0x13, 0xec, 0xe6, 0x92, 0xca, 0x82, 0xd4,
+	0x24, 0x36, 0x30, 0xd3, 0x18, 0x00, 0x96, 0x5c, 0x1f, 0x6b, 0xd1, 0x00, 0x00, 0x00,
+}
@@ -0,0 +1,166 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: columns.proto
+
+package prototype
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type ColumnStorage struct {
+	// compatiblity gurantee:
+	//     reserved field number: 1, 2, 3
+	//     reserved field name: Name, Elts, EltOffsets
+	//
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Elts                 []byte   `protobuf:"bytes,2,opt,name=Elts,proto3" json:"Elts,omitempty"`
+	EltOffsets           []uint32 `protobuf:"varint,3,rep,packed,name=EltOffsets,proto3" json:"EltOffsets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ColumnStorage) Reset()         { *m = ColumnStorage{} }
+func (m *ColumnStorage) String() string { return proto.CompactTextString(m) }
+func (*ColumnStorage) ProtoMessage()    {}
+func (*ColumnStorage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_columns_98674f1a374b9f91, []int{0}
+}
+func (m *ColumnStorage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ColumnStorage.Unmarshal(m, b)
+}
+func (m *ColumnStorage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ColumnStorage.Marshal(b, m, deterministic)
+}
+func (dst *ColumnStorage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ColumnStorage.Merge(dst, src)
+}
+func (m *ColumnStorage) XXX_Size() int {
+	return xxx_messageInfo_ColumnStorage.Size(m)
+}
+func (m *ColumnStorage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ColumnStorage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ColumnStorage proto.InternalMessageInfo
+
+func (m *ColumnStorage) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ColumnStorage) GetElts() []byte {
+	if m != nil {
+		return m.Elts
+	}
+	return nil
+}
+
+func (m *ColumnStorage) GetEltOffsets() []uint32 {
+	if m != nil {
+		return m.EltOffsets
+	}
+	return nil
+}
+
+type ColumnsStorage struct {
+	// compatiblity gurantee:
+	//     reserved field number: 1, 2, 3, 4
+	//     reserved field name: Cnt, Bitmaps, Offsets, Columns
+	//
+	Cnt                  uint32           `protobuf:"varint,1,opt,name=Cnt,proto3" json:"Cnt,omitempty"`
+	Bitmaps              []uint64         `protobuf:"varint,2,rep,packed,name=Bitmaps,proto3" json:"Bitmaps,omitempty"`
+	Offsets              []uint32         `protobuf:"varint,3,rep,packed,name=Offsets,proto3" json:"Offsets,omitempty"`
+	Columns              []*ColumnStorage `protobuf:"bytes,4,rep,name=Columns,proto3" json:"Columns,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ColumnsStorage) Reset()         { *m = ColumnsStorage{} }
+func (m *ColumnsStorage) String() string { return proto.CompactTextString(m) }
+func (*ColumnsStorage) ProtoMessage()    {}
+func (*ColumnsStorage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_columns_98674f1a374b9f91, []int{1}
+}
+func (m *ColumnsStorage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ColumnsStorage.Unmarshal(m, b)
+}
+func (m *ColumnsStorage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ColumnsStorage.Marshal(b, m, deterministic)
+}
+func (dst *ColumnsStorage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ColumnsStorage.Merge(dst, src)
+}
+func (m *ColumnsStorage) XXX_Size() int {
+	return xxx_messageInfo_ColumnsStorage.Size(m)
+}
+func (m *ColumnsStorage) XXX_DiscardUnknown() {
+	xxx_messageInfo_ColumnsStorage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ColumnsStorage proto.InternalMessageInfo
+
+func (m *ColumnsStorage) GetCnt() uint32 {
+	if m != nil {
+		return m.Cnt
+	}
+	return 0
+}
+
+func (m *ColumnsStorage) GetBitmaps() []uint64 {
+	if m != nil {
+		return m.Bitmaps
+	}
+	return nil
+}
+
+func (m *ColumnsStorage) GetOffsets() []uint32 {
+	if m != nil {
+		return m.Offsets
+	}
+	return nil
+}
+
+func (m *ColumnsStorage) GetColumns() []*ColumnStorage {
+	if m != nil {
+		return m.Columns
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ColumnStorage)(nil), "ColumnStorage")
+	proto.RegisterType((*ColumnsStorage)(nil), "ColumnsStorage")
+}
+
+func init() { proto.RegisterFile("columns.proto", fileDescriptor_columns_98674f1a374b9f91) }
+
+var fileDescriptor_columns_98674f1a374b9f91 = []byte{
+	// 179 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xe2, 0x4d, 0xce, 0xcf, 0x29,
+	0xcd, 0xcd, 0x2b, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0x0a, 0xe7, 0xe2, 0x75, 0x06, 0x0b,
+	0x04, 0x97, 0xe4, 0x17, 0x25, 0xa6, 0xa7, 0x0a, 0x09, 0x71, 0xb1, 0xf8, 0x25, 0xe6, 0xa6, 0x4a,
+	0x30, 0x2a, 0x30, 0x6a, 0x70, 0x06, 0x81, 0xd9, 0x20, 0x31, 0xd7, 0x9c, 0x92, 0x62, 0x09, 0x26,
+	0xa0, 0x18, 0x4f, 0x10, 0x98, 0x2d, 0x24, 0xc7, 0xc5, 0x05, 0xa4, 0xfd, 0xd3, 0xd2, 0x8a, 0x53,
+	0x81, 0x32, 0xcc, 0x0a, 0xcc, 0x1a, 0xbc, 0x41, 0x48, 0x22, 0x4a, 0x0d, 0x8c, 0x5c, 0x7c, 0x10,
+	0x93, 0x8b, 0x61, 0x46, 0x0b, 0x70, 0x31, 0x3b, 0xe7, 0x95, 0x80, 0x4d, 0xe6, 0x0d, 0x02, 0x31,
+	0x85, 0x24, 0xb8, 0xd8, 0x9d, 0x32, 0x4b, 0x72, 0x13, 0x0b, 0x40, 0x66, 0x33, 0x6b, 0xb0, 0x04,
+	0xc1, 0xb8, 0x20, 0x19, 0x54, 0xb3, 0x61, 0x5c, 0x21, 0x0d, 0x2e, 0x76, 0xa8, 0xb9, 0x12, 0x2c,
+	0x40, 0x19, 0x6e, 0x23, 0x3e, 0x3d, 0x14, 0x1f, 0x04, 0xc1, 0xa4, 0x9d, 0xb8, 0xa3, 0x38, 0xc1,
+	0x9e, 0x2c, 0xa9, 0x2c, 0x48, 0x4d, 0x62, 0x03, 0x33, 0x8d, 0x01, 0xd2, 0x76, 0xa6, 0xc8, 0x00,
+	0x01, 0x00, 0x00,
+}
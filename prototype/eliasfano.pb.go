@@ -0,0 +1,122 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: eliasfano.proto
+
+package prototype
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type EliasFanoStorage struct {
+	// compatiblity gurantee:
+	//     reserved field number: 1, 2, 3, 4, 5, 6
+	//     reserved field name: Cnt, LowBits, Lows, Highs, Select1Hints, Select0Hints
+	//
+	Cnt                  uint32   `protobuf:"varint,1,opt,name=Cnt,proto3" json:"Cnt,omitempty"`
+	LowBits              uint32   `protobuf:"varint,2,opt,name=LowBits,proto3" json:"LowBits,omitempty"`
+	Lows                 []uint64 `protobuf:"varint,3,rep,packed,name=Lows,proto3" json:"Lows,omitempty"`
+	Highs                []uint64 `protobuf:"varint,4,rep,packed,name=Highs,proto3" json:"Highs,omitempty"`
+	Select1Hints         []uint64 `protobuf:"varint,5,rep,packed,name=Select1Hints,proto3" json:"Select1Hints,omitempty"`
+	Select0Hints         []uint64 `protobuf:"varint,6,rep,packed,name=Select0Hints,proto3" json:"Select0Hints,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EliasFanoStorage) Reset()         { *m = EliasFanoStorage{} }
+func (m *EliasFanoStorage) String() string { return proto.CompactTextString(m) }
+func (*EliasFanoStorage) ProtoMessage()    {}
+func (*EliasFanoStorage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eliasfano_352993de602e011b, []int{0}
+}
+func (m *EliasFanoStorage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EliasFanoStorage.Unmarshal(m, b)
+}
+func (m *EliasFanoStorage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EliasFanoStorage.Marshal(b, m, deterministic)
+}
+func (dst *EliasFanoStorage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EliasFanoStorage.Merge(dst, src)
+}
+func (m *EliasFanoStorage) XXX_Size() int {
+	return xxx_messageInfo_EliasFanoStorage.Size(m)
+}
+func (m *EliasFanoStorage) XXX_DiscardUnknown() {
+	xxx_messageInfo_EliasFanoStorage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EliasFanoStorage proto.InternalMessageInfo
+
+func (m *EliasFanoStorage) GetCnt() uint32 {
+	if m != nil {
+		return m.Cnt
+	}
+	return 0
+}
+
+func (m *EliasFanoStorage) GetLowBits() uint32 {
+	if m != nil {
+		return m.LowBits
+	}
+	return 0
+}
+
+func (m *EliasFanoStorage) GetLows() []uint64 {
+	if m != nil {
+		return m.Lows
+	}
+	return nil
+}
+
+func (m *EliasFanoStorage) GetHighs() []uint64 {
+	if m != nil {
+		return m.Highs
+	}
+	return nil
+}
+
+func (m *EliasFanoStorage) GetSelect1Hints() []uint64 {
+	if m != nil {
+		return m.Select1Hints
+	}
+	return nil
+}
+
+func (m *EliasFanoStorage) GetSelect0Hints() []uint64 {
+	if m != nil {
+		return m.Select0Hints
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*EliasFanoStorage)(nil), "EliasFanoStorage")
+}
+
+func init() { proto.RegisterFile("eliasfano.proto", fileDescriptor_eliasfano_352993de602e011b) }
+
+var fileDescriptor_eliasfano_352993de602e011b = []byte{
+	// 163 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xe2, 0x4f, 0xcd, 0xc9, 0x4c,
+	0x2c, 0x4e, 0x4b, 0xcc, 0xcb, 0xd7, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0xda, 0xc0, 0xc8, 0x25,
+	0xe0, 0x0a, 0x12, 0x73, 0x03, 0x8a, 0x05, 0x97, 0xe4, 0x17, 0x25, 0xa6, 0xa7, 0x0a, 0x09, 0x70,
+	0x31, 0x3b, 0xe7, 0x95, 0x48, 0x30, 0x2a, 0x30, 0x6a, 0xf0, 0x06, 0x81, 0x98, 0x42, 0x12, 0x5c,
+	0xec, 0x3e, 0xf9, 0xe5, 0x4e, 0x99, 0x25, 0xc5, 0x12, 0x4c, 0x60, 0x51, 0x18, 0x57, 0x48, 0x88,
+	0x8b, 0x05, 0xc8, 0x2c, 0x96, 0x60, 0x56, 0x60, 0xd6, 0x60, 0x09, 0x02, 0xb3, 0x85, 0x44, 0xb8,
+	0x58, 0x3d, 0x32, 0xd3, 0x33, 0x8a, 0x25, 0x58, 0xc0, 0x82, 0x10, 0x8e, 0x90, 0x12, 0x17, 0x4f,
+	0x70, 0x6a, 0x4e, 0x6a, 0x72, 0x89, 0xa1, 0x47, 0x66, 0x1e, 0xd0, 0x20, 0x56, 0xb0, 0x24, 0x8a,
+	0x18, 0x42, 0x8d, 0x01, 0x44, 0x0d, 0x1b, 0xb2, 0x1a, 0x88, 0x98, 0x13, 0x77, 0x14, 0x27, 0xd8,
+	0xed, 0x25, 0x95, 0x05, 0xa9, 0x49, 0x6c, 0x60, 0xa6, 0x31, 0x00, 0x06, 0x6f, 0xf8, 0x24, 0xd9,
+	0x00, 0x00, 0x00,
+}
@@ -0,0 +1,96 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: indexopts.proto
+
+package prototype
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type SlimIndexOptions struct {
+	// compatiblity gurantee:
+	//     reserved field number: 1, 2, 3
+	//     reserved field name: BlockSize, CompressOffsets, Weighted
+	//
+	BlockSize            int32    `protobuf:"varint,1,opt,name=BlockSize,proto3" json:"BlockSize,omitempty"`
+	CompressOffsets      bool     `protobuf:"varint,2,opt,name=CompressOffsets,proto3" json:"CompressOffsets,omitempty"`
+	Weighted             bool     `protobuf:"varint,3,opt,name=Weighted,proto3" json:"Weighted,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SlimIndexOptions) Reset()         { *m = SlimIndexOptions{} }
+func (m *SlimIndexOptions) String() string { return proto.CompactTextString(m) }
+func (*SlimIndexOptions) ProtoMessage()    {}
+func (*SlimIndexOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_indexopts_2713089642326a35, []int{0}
+}
+func (m *SlimIndexOptions) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SlimIndexOptions.Unmarshal(m, b)
+}
+func (m *SlimIndexOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SlimIndexOptions.Marshal(b, m, deterministic)
+}
+func (dst *SlimIndexOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SlimIndexOptions.Merge(dst, src)
+}
+func (m *SlimIndexOptions) XXX_Size() int {
+	return xxx_messageInfo_SlimIndexOptions.Size(m)
+}
+func (m *SlimIndexOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_SlimIndexOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SlimIndexOptions proto.InternalMessageInfo
+
+func (m *SlimIndexOptions) GetBlockSize() int32 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
+}
+
+func (m *SlimIndexOptions) GetCompressOffsets() bool {
+	if m != nil {
+		return m.CompressOffsets
+	}
+	return false
+}
+
+func (m *SlimIndexOptions) GetWeighted() bool {
+	if m != nil {
+		return m.Weighted
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*SlimIndexOptions)(nil), "SlimIndexOptions")
+}
+
+func init() { proto.RegisterFile("indexopts.proto", fileDescriptor_indexopts_2713089642326a35) }
+
+var fileDescriptor_indexopts_2713089642326a35 = []byte{
+	// 136 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xe2, 0xcf, 0xcc, 0x4b, 0x49,
+	0xad, 0xc8, 0x2f, 0x28, 0x29, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0x2a, 0xe3, 0x12, 0x08,
+	0xce, 0xc9, 0xcc, 0xf5, 0x04, 0x09, 0xfb, 0x17, 0x94, 0x64, 0xe6, 0xe7, 0x15, 0x0b, 0xc9, 0x70,
+	0x71, 0x3a, 0xe5, 0xe4, 0x27, 0x67, 0x07, 0x67, 0x56, 0xa5, 0x4a, 0x30, 0x2a, 0x30, 0x6a, 0xb0,
+	0x06, 0x21, 0x04, 0x84, 0x34, 0xb8, 0xf8, 0x9d, 0xf3, 0x73, 0x0b, 0x8a, 0x52, 0x8b, 0x8b, 0xfd,
+	0xd3, 0xd2, 0x8a, 0x53, 0x4b, 0x8a, 0x25, 0x98, 0x80, 0x6a, 0x38, 0x82, 0xd0, 0x85, 0x85, 0xa4,
+	0xb8, 0x38, 0xc2, 0x53, 0x33, 0xd3, 0x33, 0x4a, 0x52, 0x53, 0x24, 0x98, 0xc1, 0x4a, 0xe0, 0x7c,
+	0x27, 0xee, 0x28, 0x4e, 0xb0, 0x03, 0x4a, 0x2a, 0x0b, 0x52, 0x93, 0xd8, 0xc0, 0x4c, 0x63, 0x00,
+	0x47, 0x78, 0xeb, 0x63, 0x9e, 0x00, 0x00, 0x00,
+}
@@ -0,0 +1,103 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: offsets.proto
+
+package prototype
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type PackedOffsets struct {
+	// compatiblity gurantee:
+	//     reserved field number: 1, 2, 3, 4
+	//     reserved field name: Cnt, Bases, Starts, Words
+	//
+	Cnt                  uint32   `protobuf:"varint,1,opt,name=Cnt,proto3" json:"Cnt,omitempty"`
+	Bases                []uint64 `protobuf:"varint,2,rep,packed,name=Bases,proto3" json:"Bases,omitempty"`
+	Starts               []uint32 `protobuf:"varint,3,rep,packed,name=Starts,proto3" json:"Starts,omitempty"`
+	Words                []uint64 `protobuf:"varint,4,rep,packed,name=Words,proto3" json:"Words,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PackedOffsets) Reset()         { *m = PackedOffsets{} }
+func (m *PackedOffsets) String() string { return proto.CompactTextString(m) }
+func (*PackedOffsets) ProtoMessage()    {}
+func (*PackedOffsets) Descriptor() ([]byte, []int) {
+	return fileDescriptor_offsets_14bfb9c1a7f66752, []int{0}
+}
+func (m *PackedOffsets) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PackedOffsets.Unmarshal(m, b)
+}
+func (m *PackedOffsets) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PackedOffsets.Marshal(b, m, deterministic)
+}
+func (dst *PackedOffsets) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PackedOffsets.Merge(dst, src)
+}
+func (m *PackedOffsets) XXX_Size() int {
+	return xxx_messageInfo_PackedOffsets.Size(m)
+}
+func (m *PackedOffsets) XXX_DiscardUnknown() {
+	xxx_messageInfo_PackedOffsets.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PackedOffsets proto.InternalMessageInfo
+
+func (m *PackedOffsets) GetCnt() uint32 {
+	if m != nil {
+		return m.Cnt
+	}
+	return 0
+}
+
+func (m *PackedOffsets) GetBases() []uint64 {
+	if m != nil {
+		return m.Bases
+	}
+	return nil
+}
+
+func (m *PackedOffsets) GetStarts() []uint32 {
+	if m != nil {
+		return m.Starts
+	}
+	return nil
+}
+
+func (m *PackedOffsets) GetWords() []uint64 {
+	if m != nil {
+		return m.Words
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*PackedOffsets)(nil), "PackedOffsets")
+}
+
+func init() { proto.RegisterFile("offsets.proto", fileDescriptor_offsets_14bfb9c1a7f66752) }
+
+var fileDescriptor_offsets_14bfb9c1a7f66752 = []byte{
+	// 126 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xe2, 0xcd, 0x4f, 0x4b, 0x2b,
+	0x4e, 0x2d, 0x29, 0xd6, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0x4a, 0xe5, 0xe2, 0x0d, 0x48, 0x4c,
+	0xce, 0x4e, 0x4d, 0xf1, 0x87, 0x08, 0x0b, 0x09, 0x70, 0x31, 0x3b, 0xe7, 0x95, 0x48, 0x30, 0x2a,
+	0x30, 0x6a, 0xf0, 0x06, 0x81, 0x98, 0x42, 0x22, 0x5c, 0xac, 0x4e, 0x89, 0xc5, 0xa9, 0xc5, 0x12,
+	0x4c, 0x0a, 0xcc, 0x1a, 0x2c, 0x41, 0x10, 0x8e, 0x90, 0x18, 0x17, 0x5b, 0x70, 0x49, 0x62, 0x51,
+	0x49, 0xb1, 0x04, 0x33, 0x50, 0x98, 0x37, 0x08, 0xca, 0x03, 0xa9, 0x0e, 0xcf, 0x2f, 0x4a, 0x29,
+	0x96, 0x60, 0x81, 0xa8, 0x06, 0x73, 0x9c, 0xb8, 0xa3, 0x38, 0xc1, 0xf6, 0x95, 0x54, 0x16, 0xa4,
+	0x26, 0xb1, 0x81, 0x99, 0xc6, 0x00, 0xc7, 0xa1, 0x09, 0x64, 0x8b, 0x00, 0x00, 0x00,
+}
@@ -0,0 +1,94 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: packed.proto
+
+package prototype
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type PackedArrayStorage struct {
+	// compatiblity gurantee:
+	//     reserved field number: 1, 2, 3
+	//     reserved field name: Width, Cnt, Words
+	//
+	Width                uint32   `protobuf:"varint,1,opt,name=Width,proto3" json:"Width,omitempty"`
+	Cnt                  uint32   `protobuf:"varint,2,opt,name=Cnt,proto3" json:"Cnt,omitempty"`
+	Words                []uint64 `protobuf:"varint,3,rep,packed,name=Words,proto3" json:"Words,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PackedArrayStorage) Reset()         { *m = PackedArrayStorage{} }
+func (m *PackedArrayStorage) String() string { return proto.CompactTextString(m) }
+func (*PackedArrayStorage) ProtoMessage()    {}
+func (*PackedArrayStorage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_packed_23b408dbf2faa2b1, []int{0}
+}
+func (m *PackedArrayStorage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PackedArrayStorage.Unmarshal(m, b)
+}
+func (m *PackedArrayStorage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PackedArrayStorage.Marshal(b, m, deterministic)
+}
+func (dst *PackedArrayStorage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PackedArrayStorage.Merge(dst, src)
+}
+func (m *PackedArrayStorage) XXX_Size() int {
+	return xxx_messageInfo_PackedArrayStorage.Size(m)
+}
+func (m *PackedArrayStorage) XXX_DiscardUnknown() {
+	xxx_messageInfo_PackedArrayStorage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PackedArrayStorage proto.InternalMessageInfo
+
+func (m *PackedArrayStorage) GetWidth() uint32 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
+}
+
+func (m *PackedArrayStorage) GetCnt() uint32 {
+	if m != nil {
+		return m.Cnt
+	}
+	return 0
+}
+
+func (m *PackedArrayStorage) GetWords() []uint64 {
+	if m != nil {
+		return m.Words
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*PackedArrayStorage)(nil), "PackedArrayStorage")
+}
+
+func init() { proto.RegisterFile("packed.proto", fileDescriptor_packed_23b408dbf2faa2b1) }
+
+var fileDescriptor_packed_23b408dbf2faa2b1 = []byte{
+	// 112 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xe2, 0x29, 0x48, 0x4c, 0xce,
+	0x4e, 0x4d, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0x0a, 0xe2, 0x12, 0x0a, 0x00, 0xf3, 0x1d,
+	0x8b, 0x8a, 0x12, 0x2b, 0x83, 0x4b, 0xf2, 0x8b, 0x12, 0xd3, 0x53, 0x85, 0x44, 0xb8, 0x58, 0xc3,
+	0x33, 0x53, 0x4a, 0x32, 0x24, 0x18, 0x15, 0x18, 0x35, 0x78, 0x83, 0x20, 0x1c, 0x21, 0x01, 0x2e,
+	0x66, 0xe7, 0xbc, 0x12, 0x09, 0x26, 0xb0, 0x18, 0x88, 0x09, 0x56, 0x97, 0x5f, 0x94, 0x52, 0x2c,
+	0xc1, 0xac, 0xc0, 0xac, 0xc1, 0x12, 0x04, 0xe1, 0x38, 0x71, 0x47, 0x71, 0x82, 0x0d, 0x2f, 0xa9,
+	0x2c, 0x48, 0x4d, 0x62, 0x03, 0x33, 0x8d, 0x01, 0xb3, 0xb5, 0xe3, 0x77, 0x77, 0x00, 0x00, 0x00,
+}
@@ -7,3 +7,11 @@
 package prototype
 
 //go:generate protoc --proto_path=. --go_out=. array.proto
+//go:generate protoc --proto_path=. --go_out=. offsets.proto
+//go:generate protoc --proto_path=. --go_out=. array64.proto
+//go:generate protoc --proto_path=. --go_out=. roaring.proto
+//go:generate protoc --proto_path=. --go_out=. bitvector.proto
+//go:generate protoc --proto_path=. --go_out=. packed.proto
+//go:generate protoc --proto_path=. --go_out=. eliasfano.proto
+//go:generate protoc --proto_path=. --go_out=. columns.proto
+//go:generate protoc --proto_path=. --go_out=. indexopts.proto
@@ -0,0 +1,139 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: roaring.proto
+
+package prototype
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type RoaringStorage struct {
+	// compatiblity gurantee:
+	//     reserved field number: 1, 2, 3, 4, 5, 6, 7, 8
+	//     reserved field name: Cnt, Keys, Kinds, Starts, Offsets, Words, Elts, EltOffsets
+	//
+	Cnt                  uint32   `protobuf:"varint,1,opt,name=Cnt,proto3" json:"Cnt,omitempty"`
+	Keys                 []uint32 `protobuf:"varint,2,rep,packed,name=Keys,proto3" json:"Keys,omitempty"`
+	Kinds                []uint32 `protobuf:"varint,3,rep,packed,name=Kinds,proto3" json:"Kinds,omitempty"`
+	Starts               []uint32 `protobuf:"varint,4,rep,packed,name=Starts,proto3" json:"Starts,omitempty"`
+	Offsets              []uint32 `protobuf:"varint,5,rep,packed,name=Offsets,proto3" json:"Offsets,omitempty"`
+	Words                []uint64 `protobuf:"varint,6,rep,packed,name=Words,proto3" json:"Words,omitempty"`
+	Elts                 []byte   `protobuf:"bytes,7,opt,name=Elts,proto3" json:"Elts,omitempty"`
+	EltOffsets           []uint32 `protobuf:"varint,8,rep,packed,name=EltOffsets,proto3" json:"EltOffsets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RoaringStorage) Reset()         { *m = RoaringStorage{} }
+func (m *RoaringStorage) String() string { return proto.CompactTextString(m) }
+func (*RoaringStorage) ProtoMessage()    {}
+func (*RoaringStorage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_roaring_972f30a4626a4e2c, []int{0}
+}
+func (m *RoaringStorage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RoaringStorage.Unmarshal(m, b)
+}
+func (m *RoaringStorage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RoaringStorage.Marshal(b, m, deterministic)
+}
+func (dst *RoaringStorage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoaringStorage.Merge(dst, src)
+}
+func (m *RoaringStorage) XXX_Size() int {
+	return xxx_messageInfo_RoaringStorage.Size(m)
+}
+func (m *RoaringStorage) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoaringStorage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoaringStorage proto.InternalMessageInfo
+
+func (m *RoaringStorage) GetCnt() uint32 {
+	if m != nil {
+		return m.Cnt
+	}
+	return 0
+}
+
+func (m *RoaringStorage) GetKeys() []uint32 {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *RoaringStorage) GetKinds() []uint32 {
+	if m != nil {
+		return m.Kinds
+	}
+	return nil
+}
+
+func (m *RoaringStorage) GetStarts() []uint32 {
+	if m != nil {
+		return m.Starts
+	}
+	return nil
+}
+
+func (m *RoaringStorage) GetOffsets() []uint32 {
+	if m != nil {
+		return m.Offsets
+	}
+	return nil
+}
+
+func (m *RoaringStorage) GetWords() []uint64 {
+	if m != nil {
+		return m.Words
+	}
+	return nil
+}
+
+func (m *RoaringStorage) GetElts() []byte {
+	if m != nil {
+		return m.Elts
+	}
+	return nil
+}
+
+func (m *RoaringStorage) GetEltOffsets() []uint32 {
+	if m != nil {
+		return m.EltOffsets
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*RoaringStorage)(nil), "RoaringStorage")
+}
+
+func init() { proto.RegisterFile("roaring.proto", fileDescriptor_roaring_972f30a4626a4e2c) }
+
+var fileDescriptor_roaring_972f30a4626a4e2c = []byte{
+	// 182 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0xe2, 0x2d, 0xca, 0x4f, 0x2c,
+	0xca, 0xcc, 0x4b, 0xd7, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x57, 0x3a, 0xc1, 0xc8, 0xc5, 0x17, 0x04,
+	0x11, 0x09, 0x2e, 0xc9, 0x2f, 0x4a, 0x4c, 0x4f, 0x15, 0x12, 0xe0, 0x62, 0x76, 0xce, 0x2b, 0x91,
+	0x60, 0x54, 0x60, 0xd4, 0xe0, 0x0d, 0x02, 0x31, 0x85, 0x84, 0xb8, 0x58, 0xbc, 0x53, 0x2b, 0x8b,
+	0x25, 0x98, 0x14, 0x98, 0x81, 0x42, 0x60, 0xb6, 0x90, 0x08, 0x17, 0xab, 0x77, 0x66, 0x5e, 0x4a,
+	0xb1, 0x04, 0x33, 0x58, 0x10, 0xc2, 0x11, 0x12, 0xe3, 0x62, 0x0b, 0x2e, 0x49, 0x2c, 0x2a, 0x29,
+	0x96, 0x60, 0x01, 0x0b, 0x43, 0x79, 0x42, 0x12, 0x5c, 0xec, 0xfe, 0x69, 0x69, 0xc5, 0xa9, 0x40,
+	0x09, 0x56, 0xb0, 0x04, 0x8c, 0x0b, 0x32, 0x27, 0x3c, 0xbf, 0x08, 0x68, 0x0e, 0x1b, 0x50, 0x9c,
+	0x25, 0x08, 0xc2, 0x01, 0xd9, 0xe8, 0x9a, 0x03, 0x54, 0xcc, 0x0e, 0x74, 0x04, 0x4f, 0x10, 0x98,
+	0x2d, 0x24, 0xc7, 0xc5, 0x05, 0xa4, 0x61, 0xc6, 0x70, 0x80, 0x8d, 0x41, 0x12, 0x71, 0xe2, 0x8e,
+	0xe2, 0x04, 0xfb, 0xa9, 0xa4, 0xb2, 0x20, 0x35, 0x89, 0x0d, 0xcc, 0x34, 0x06, 0x00, 0x9b, 0x6c,
+	0x40, 0x28, 0xef, 0x00, 0x00, 0x00,
+}
@@ -7,7 +7,9 @@ import (
 	"io"
 	mrand "math/rand"
 	"runtime"
+	"sort"
 
+	"github.com/openacid/slim/index"
 	"github.com/openacid/slim/marshal"
 	"github.com/openacid/slim/trie"
 )
@@ -66,6 +68,8 @@ func readMem() int64 {
 
 func main() {
 	compareTrieMapMemUse()
+	fmt.Println()
+	compareIndexMemUse()
 }
 
 func compareTrieMapMemUse() {
@@ -233,3 +237,89 @@ func getMapMem(keyCnt, keyLen int64) int64 {
 
 	return size
 }
+
+func compareIndexMemUse() {
+
+	fmt.Printf("| %s | %s | %s | %s | %s |\n",
+		"Key Count", "Record Size", "Record Placement", "SlimIndex Size (Byte/key)",
+		"Compressed Offsets SlimIndex Size (Byte/key)")
+
+	fmt.Printf("| --- | --- | --- | --- | --- |\n")
+
+	for _, cnt := range []int64{1000, 2000, 5000} {
+		for _, recSize := range []int64{64, 1024} {
+			for _, shuffled := range []bool{false, true} {
+
+				size, err := getIndexMem(cnt, recSize, shuffled)
+				if err != nil {
+					fmt.Printf("failed to get index size: %v", err)
+				}
+
+				compressedSize, err := getIndexMem(cnt, recSize, shuffled, index.CompressOffsets())
+				if err != nil {
+					fmt.Printf("failed to get index size: %v", err)
+				}
+
+				placement := "sorted"
+				if shuffled {
+					placement = "shuffled"
+				}
+
+				fmt.Printf("| %5d | %5d | %8s | %6.1f | %6.1f |\n",
+					cnt, recSize, placement,
+					float64(size)/float64(cnt), float64(compressedSize)/float64(cnt))
+			}
+		}
+	}
+}
+
+// makeIndexItems makes sorted keys and the offsets of records in a file,
+// where records are about `recSize` bytes.
+// Records are stored in key order, or in random order if `shuffled` is true.
+func makeIndexItems(cnt, recSize int64, shuffled bool) []index.OffsetIndexItem {
+	keys := makeKeys(cnt, 32)
+	sort.Strings(keys)
+
+	offsets := make([]int64, cnt)
+	offset := int64(0)
+	for i := range offsets {
+		offsets[i] = offset
+		offset += recSize/2 + mrand.Int63n(recSize)
+	}
+
+	perm := make([]int, cnt)
+	for i := range perm {
+		perm[i] = i
+	}
+	if shuffled {
+		perm = mrand.Perm(int(cnt))
+	}
+
+	items := make([]index.OffsetIndexItem, cnt)
+	for i, k := range keys {
+		items[i] = index.OffsetIndexItem{Key: k, Offset: offsets[perm[i]]}
+	}
+	return items
+}
+
+func getIndexMem(cnt, recSize int64, shuffled bool, opts ...index.Option) (size int64, err error) {
+
+	items := makeIndexItems(cnt, recSize, shuffled)
+
+	memStart := readMem()
+
+	st, err := index.NewSlimIndex(items, nil, opts...)
+	if err != nil {
+		return
+	}
+
+	memEnd := readMem()
+
+	size = memEnd - memStart
+
+	// reference them or memory is freed
+	runtime.KeepAlive(st)
+	runtime.KeepAlive(items)
+
+	return
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/openacid/slim/array"
 	"github.com/openacid/slim/bits"
+	"github.com/openacid/slim/marshal"
 	"github.com/openacid/slim/serialize"
 	"github.com/openacid/slim/strhelper"
 )
@@ -142,7 +143,18 @@ func (c stepConv) GetMarshaledSize(b []byte) int {
 // NewSlimTrie create an empty SlimTrie.
 // Argument c implements a array.Converter to convert user data to serialized
 // bytes and back.
+// If c is nil, it is inferred from the element type of values by
+// marshal.GetSliceEltMarshaller, e.g., marshal.F64 for []float64.
 func NewSlimTrie(c array.Converter, keys []string, values interface{}) (*SlimTrie, error) {
+
+	if c == nil && values != nil {
+		m, err := marshal.GetSliceEltMarshaller(values)
+		if err != nil {
+			return nil, err
+		}
+		c = m
+	}
+
 	var step uint16
 	st := &SlimTrie{
 		Children: array.Array32{Converter: childConv{child: &children{}}},
@@ -347,6 +359,49 @@ func (st *SlimTrie) searchWords(key []byte) (ltVal, eqVal, gtVal interface{}) {
 // A non-nil return value does not mean the `key` exists.
 // An in-existent `key` also could matches partial info stored in SlimTrie.
 func (st *SlimTrie) Search(key string) (ltVal, eqVal, gtVal interface{}) {
+	ltIdx, eqIdx, gtIdx := st.searchLeaves(key)
+
+	if ltIdx != -1 {
+		ltVal = st.Leaves.Get(uint32(ltIdx))
+	}
+	if gtIdx != -1 {
+		gtVal = st.Leaves.Get(uint32(gtIdx))
+	}
+	if eqIdx != -1 {
+		eqVal = st.Leaves.Get(uint32(eqIdx))
+	}
+
+	return
+}
+
+// SearchLeafOrdinal is similar to Search except it returns the positions of
+// the 3 values in SlimTrie.Leaves instead of the values.
+// A position is -1 if there is no such value.
+//
+// Values in SlimTrie.Leaves are stored in the order of node id, not in the
+// order of keys.
+func (st *SlimTrie) SearchLeafOrdinal(key string) (lt, eq, gt int32) {
+	ltIdx, eqIdx, gtIdx := st.searchLeaves(key)
+	return st.leafOrdinal(ltIdx), st.leafOrdinal(eqIdx), st.leafOrdinal(gtIdx)
+}
+
+// leafOrdinal returns the position of the value of leaf node `idx` in
+// SlimTrie.Leaves, or -1 if `idx` is -1 or it is not a leaf.
+func (st *SlimTrie) leafOrdinal(idx int32) int32 {
+	if idx == -1 {
+		return -1
+	}
+
+	i, found := st.Leaves.GetEltIndex(uint32(idx))
+	if !found {
+		return -1
+	}
+	return int32(i)
+}
+
+// searchLeaves returns the node id of the 3 leaf nodes Search looks for, or -1
+// if such a leaf node does not exist.
+func (st *SlimTrie) searchLeaves(key string) (ltLeafIdx, eqLeafIdx, gtLeafIdx int32) {
 	eqIdx, ltIdx, gtIdx := int32(0), int32(-1), int32(-1)
 	ltLeaf := false
 
@@ -393,17 +448,45 @@ func (st *SlimTrie) Search(key string) (ltVal, eqVal, gtVal interface{}) {
 		}
 	}
 
+	ltLeafIdx, eqLeafIdx, gtLeafIdx = int32(-1), eqIdx, int32(-1)
+
 	if ltIdx != -1 {
 		if ltLeaf {
-			ltVal = st.Leaves.Get(uint32(ltIdx))
+			ltLeafIdx = ltIdx
 		} else {
-			rmIdx := st.rightMost(uint16(ltIdx))
-			ltVal = st.Leaves.Get(uint32(rmIdx))
+			ltLeafIdx = int32(st.rightMost(uint16(ltIdx)))
 		}
 	}
 	if gtIdx != -1 {
-		fmIdx := st.leftMost(uint16(gtIdx))
-		gtVal = st.Leaves.Get(uint32(fmIdx))
+		gtLeafIdx = int32(st.leftMost(uint16(gtIdx)))
+	}
+
+	return
+}
+
+// SearchExact is similar to Search except that the returned values are exact
+// even if `key` is not present.
+//
+// SlimTrie does not store words shared by all keys of a sub-trie, thus Search
+// might route an absent key into a wrong sub-trie.
+// SearchExact reads the complete key of one leaf with `keyOf`, which returns
+// the key of a leaf value, and finds the first word `key` differs from it.
+// The sub-trie in which that word is skipped is then entirely less or greater
+// than `key`, and the neighbors of the sub-trie are the right values.
+//
+// Thus it calls `keyOf` once, or never if the trie is empty.
+// If `keyOf` returns false, it returns the same as Search.
+func (st *SlimTrie) SearchExact(key string, keyOf func(val interface{}) (string, bool)) (ltVal, eqVal, gtVal interface{}) {
+
+	ltIdx, eqIdx, gtIdx := st.searchLeavesExact(key, func(idx int32) (string, bool) {
+		return keyOf(st.Leaves.Get(uint32(idx)))
+	})
+
+	if ltIdx != -1 {
+		ltVal = st.Leaves.Get(uint32(ltIdx))
+	}
+	if gtIdx != -1 {
+		gtVal = st.Leaves.Get(uint32(gtIdx))
 	}
 	if eqIdx != -1 {
 		eqVal = st.Leaves.Get(uint32(eqIdx))
@@ -412,6 +495,168 @@ func (st *SlimTrie) Search(key string) (ltVal, eqVal, gtVal interface{}) {
 	return
 }
 
+// SearchLeafOrdinalExact is similar to SearchExact except it returns the
+// positions of the 3 values in SlimTrie.Leaves as SearchLeafOrdinal does, and
+// `keyOf` returns the key of a position.
+func (st *SlimTrie) SearchLeafOrdinalExact(key string, keyOf func(ord int32) (string, bool)) (lt, eq, gt int32) {
+
+	ltIdx, eqIdx, gtIdx := st.searchLeavesExact(key, func(idx int32) (string, bool) {
+		return keyOf(st.leafOrdinal(idx))
+	})
+
+	return st.leafOrdinal(ltIdx), st.leafOrdinal(eqIdx), st.leafOrdinal(gtIdx)
+}
+
+// pathNode is a node `key` passes by when searching it, with the neighbors of
+// its sub-trie.
+type pathNode struct {
+	idx int32
+	// pos is the position of the word to compare at this node.
+	// All keys in the sub-trie share the words before it.
+	pos uint16
+
+	ltIdx  int32
+	ltLeaf bool
+	gtIdx  int32
+}
+
+// searchLeavesExact is similar to searchLeaves except the returned leaf nodes
+// are exact, with the help of `keyOf`, which returns the key of a leaf node.
+func (st *SlimTrie) searchLeavesExact(key string, keyOf func(idx int32) (string, bool)) (ltLeafIdx, eqLeafIdx, gtLeafIdx int32) {
+
+	eqIdx, ltIdx, gtIdx := int32(0), int32(-1), int32(-1)
+	ltLeaf := false
+
+	path := []pathNode{{idx: 0, pos: 0, ltIdx: -1, gtIdx: -1}}
+
+	lenWords := 2 * uint16(len(key))
+
+	for idx := uint16(0); ; {
+		word := wordAt(key, idx)
+
+		li, ei, ri, leaf := st.neighborBranches(uint16(eqIdx), word)
+		if li >= 0 {
+			ltIdx = li
+			ltLeaf = leaf
+		}
+
+		if ri >= 0 {
+			gtIdx = ri
+		}
+
+		eqIdx = ei
+		if eqIdx == -1 {
+			break
+		}
+
+		if word == LeafWord {
+			break
+		}
+
+		idx += st.getStep(uint16(eqIdx))
+		path = append(path, pathNode{eqIdx, idx, ltIdx, ltLeaf, gtIdx})
+
+		if idx > lenWords {
+			gtIdx = eqIdx
+			eqIdx = -1
+			break
+		}
+	}
+
+	lt, gt := st.boundLeaves(ltIdx, ltLeaf, gtIdx)
+
+	// An empty trie has only the root, which has neither a leaf nor children.
+	if len(path) == 1 && !st.Leaves.Has(0) && !st.Children.Has(0) {
+		return lt, eqIdx, gt
+	}
+
+	last := path[len(path)-1]
+	sample := int32(st.leftMost(uint16(last.idx)))
+	sampleKey, ok := keyOf(sample)
+	if !ok {
+		return lt, eqIdx, gt
+	}
+
+	if key == sampleKey {
+		return lt, eqIdx, gt
+	}
+
+	less := key < sampleKey
+	diff := firstDiffWord(key, sampleKey)
+
+	for _, n := range path[1:] {
+		if n.pos <= diff {
+			continue
+		}
+
+		// The word at `diff` is skipped in the sub-trie of `n`, thus all keys
+		// in it are less or greater than `key`.
+		lt, gt = st.boundLeaves(n.ltIdx, n.ltLeaf, n.gtIdx)
+		if less {
+			return lt, -1, int32(st.leftMost(uint16(n.idx)))
+		}
+		return int32(st.rightMost(uint16(n.idx))), -1, gt
+	}
+
+	// Every word compared is the same as the sample key, neighbors are exact.
+	if eqIdx != -1 {
+		if less {
+			gt = eqIdx
+		} else {
+			lt = eqIdx
+		}
+	}
+
+	return lt, -1, gt
+}
+
+// boundLeaves returns the greatest leaf node in sub-trie `ltIdx`, or `ltIdx`
+// if it is a leaf, and the smallest leaf node in sub-trie `gtIdx`.
+func (st *SlimTrie) boundLeaves(ltIdx int32, ltLeaf bool, gtIdx int32) (int32, int32) {
+
+	lt, gt := int32(-1), int32(-1)
+
+	if ltIdx != -1 {
+		if ltLeaf {
+			lt = ltIdx
+		} else {
+			lt = int32(st.rightMost(uint16(ltIdx)))
+		}
+	}
+	if gtIdx != -1 {
+		gt = int32(st.leftMost(uint16(gtIdx)))
+	}
+
+	return lt, gt
+}
+
+// wordAt returns the `i`-th 4-bit word of `key`, or LeafWord at the end of
+// `key`.
+func wordAt(key string, i uint16) byte {
+	if i == 2*uint16(len(key)) {
+		return LeafWord
+	}
+	if i&1 == 1 {
+		return key[i>>1] & 0x0f
+	}
+	return (key[i>>1] & 0xf0) >> 4
+}
+
+// firstDiffWord returns the position of the first 4-bit word `a` and `b`
+// differ at, or the length in words of the shorter one if it is a prefix of
+// the other.
+func firstDiffWord(a, b string) uint16 {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	if i < len(a) && i < len(b) && a[i]&0xf0 == b[i]&0xf0 {
+		return uint16(2*i + 1)
+	}
+	return uint16(2 * i)
+}
+
 // just return equal value for trie.Search benchmark
 
 // Get the value of the specified key from SlimTrie.
@@ -424,6 +669,32 @@ func (st *SlimTrie) Search(key string) (ltVal, eqVal, gtVal interface{}) {
 // SlimTrie tell you "WHERE IT POSSIBLY BE", rather than "IT IS JUST THERE".
 func (st *SlimTrie) Get(key string) (eqVal interface{}) {
 
+	eqIdx := st.getLeaf(key)
+	if eqIdx != -1 {
+		eqVal = st.Leaves.Get(uint32(eqIdx))
+	}
+
+	return
+}
+
+// GetLeafOrdinal is similar to Get except it returns the position of the value
+// in SlimTrie.Leaves, and a bool indicating if there is such a value.
+//
+// Values in SlimTrie.Leaves are stored in the order of node id, not in the
+// order of keys.
+// Thus it can be used to look up values stored outside of SlimTrie.
+func (st *SlimTrie) GetLeafOrdinal(key string) (uint32, bool) {
+	eqIdx := st.getLeaf(key)
+	if eqIdx == -1 {
+		return 0, false
+	}
+
+	return st.Leaves.GetEltIndex(uint32(eqIdx))
+}
+
+// getLeaf returns the id of the node `key` leads to, or -1.
+func (st *SlimTrie) getLeaf(key string) int32 {
+
 	var word byte
 	eqIdx := int32(0)
 
@@ -453,32 +724,85 @@ func (st *SlimTrie) Get(key string) (eqVal interface{}) {
 		}
 	}
 
-	if eqIdx != -1 {
-		eqVal = st.Leaves.Get(uint32(eqIdx))
+	return eqIdx
+}
+
+// PrefixNode returns the id of the node whose sub-trie contains all keys
+// starting with `prefix`, or -1 if there is no such key.
+//
+// Because SlimTrie does not store skipped words, the sub-trie might also
+// contain keys without `prefix`.
+func (st *SlimTrie) PrefixNode(prefix string) int32 {
+
+	eqIdx := int32(0)
+
+	// string to 4-bit words
+	lenWords := 2 * uint16(len(prefix))
+
+	for idx := uint16(0); idx < lenWords; {
+
+		shift := 4 - (idx&1)*4
+		word := ((prefix[idx>>1] >> shift) & 0x0f)
+
+		eqIdx = st.nextBranch(uint16(eqIdx), word)
+		if eqIdx == -1 {
+			break
+		}
+
+		idx += st.getStep(uint16(eqIdx))
 	}
 
-	return
+	return eqIdx
 }
 
-func (st *SlimTrie) getChild(idx uint16) *children {
-	cval, found := st.Children.Get2(uint32(idx))
-	if found {
-		return cval.(*children)
+// ChildNodes returns ids of the children of node `idx`, in key order.
+// A leaf value of node `idx` itself is not a child.
+func (st *SlimTrie) ChildNodes(idx int32) []int32 {
+
+	ch, ok := st.getChild(uint16(idx))
+	if !ok {
+		return nil
 	}
-	return nil
+
+	ids := make([]int32, 0, bits.OnesCount64Before(uint64(ch.Bitmap), 64))
+	for i := uint16(0); i < uint16(LeafWord); i++ {
+		if (ch.Bitmap >> i & 1) == 1 {
+			ids = append(ids, int32(getChildIdx(ch, i)))
+		}
+	}
+
+	return ids
+}
+
+// getChild returns the children of node `idx` and a bool indicating if it is
+// an inner node.
+//
+// It decodes bytes in place instead of with childConv, which reuses one
+// `children` and is not safe for concurrent use.
+// Thus lookups on a SlimTrie are safe for concurrent use.
+func (st *SlimTrie) getChild(idx uint16) (children, bool) {
+	b, found := st.Children.GetBytes(uint32(idx), 4)
+	if !found {
+		return children{}, false
+	}
+
+	return children{
+		Bitmap: binary.LittleEndian.Uint16(b[:2]),
+		Offset: binary.LittleEndian.Uint16(b[2:4]),
+	}, true
 }
 
 func (st *SlimTrie) getStep(idx uint16) uint16 {
-	step := st.Steps.Get(uint32(idx))
-	if step == nil {
+	b, found := st.Steps.GetBytes(uint32(idx), 2)
+	if !found {
 		return uint16(1)
 	}
-	return *(step.(*uint16))
+	return binary.LittleEndian.Uint16(b)
 }
 
 // getChildIdx returns the id of the specified child.
 // This function does not check if the specified child `offset` exists or not.
-func getChildIdx(ch *children, word uint16) uint16 {
+func getChildIdx(ch children, word uint16) uint16 {
 	chNum := bits.OnesCount64Before(uint64(ch.Bitmap), uint(word))
 	return ch.Offset + uint16(chNum)
 }
@@ -500,8 +824,8 @@ func (st *SlimTrie) neighborBranches(idx uint16, word byte) (ltIdx, eqIdx, rtIdx
 		}
 	}
 
-	ch := st.getChild(idx)
-	if ch == nil {
+	ch, ok := st.getChild(idx)
+	if !ok {
 		return
 	}
 
@@ -535,8 +859,8 @@ func (st *SlimTrie) neighborBranches(idx uint16, word byte) (ltIdx, eqIdx, rtIdx
 
 func (st *SlimTrie) nextBranch(idx uint16, word byte) int32 {
 
-	ch := st.getChild(idx)
-	if ch == nil {
+	ch, ok := st.getChild(idx)
+	if !ok {
 		return -1
 	}
 
@@ -553,7 +877,10 @@ func (st *SlimTrie) leftMost(idx uint16) uint16 {
 			return idx
 		}
 
-		ch := st.getChild(idx)
+		ch, ok := st.getChild(idx)
+		if !ok {
+			return idx
+		}
 		idx = ch.Offset
 	}
 }
@@ -565,7 +892,10 @@ func (st *SlimTrie) rightMost(idx uint16) uint16 {
 			return idx
 		}
 
-		ch := st.getChild(idx)
+		ch, ok := st.getChild(idx)
+		if !ok {
+			return idx
+		}
 
 		// count number of all children
 		// TODO use bits.PopCntXX without before.
@@ -575,8 +905,8 @@ func (st *SlimTrie) rightMost(idx uint16) uint16 {
 	}
 }
 
-// getMarshalSize returns the serialized length in byte of a SlimTrie.
-func (st *SlimTrie) getMarshalSize() int64 {
+// GetMarshalSize returns the serialized length in byte of a SlimTrie.
+func (st *SlimTrie) GetMarshalSize() int64 {
 	cSize := serialize.GetMarshalSize(&st.Children)
 	sSize := serialize.GetMarshalSize(&st.Steps)
 	lSize := serialize.GetMarshalSize(&st.Leaves)
@@ -584,8 +914,8 @@ func (st *SlimTrie) getMarshalSize() int64 {
 	return cSize + sSize + lSize
 }
 
-// marshal serializes it to byte stream.
-func (st *SlimTrie) marshal(writer io.Writer) (cnt int64, err error) {
+// Marshal serializes it to byte stream.
+func (st *SlimTrie) Marshal(writer io.Writer) (cnt int64, err error) {
 	var n int64
 
 	if n, err = serialize.Marshal(writer, &st.Children); err != nil {
@@ -612,7 +942,7 @@ func (st *SlimTrie) marshal(writer io.Writer) (cnt int64, err error) {
 func (st *SlimTrie) marshalAt(f *os.File, offset int64) (cnt int64, err error) {
 
 	buf := new(bytes.Buffer)
-	if cnt, err = st.marshal(buf); err != nil {
+	if cnt, err = st.Marshal(buf); err != nil {
 		return 0, err
 	}
 
@@ -623,8 +953,11 @@ func (st *SlimTrie) marshalAt(f *os.File, offset int64) (cnt int64, err error) {
 	return cnt, nil
 }
 
-// unmarshal de-serializes and loads SlimTrie from a byte stream.
-func (st *SlimTrie) unmarshal(reader io.Reader) error {
+// Unmarshal de-serializes and loads SlimTrie from a byte stream.
+//
+// The SlimTrie must be created with NewSlimTrie with the same Converter used
+// when it is serialized.
+func (st *SlimTrie) Unmarshal(reader io.Reader) error {
 	if err := serialize.Unmarshal(reader, &st.Children); err != nil {
 		return err
 	}
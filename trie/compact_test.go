@@ -3,12 +3,17 @@ package trie
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"math/rand"
 	"os"
 	"reflect"
+	"sort"
+	"sync"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/openacid/slim/array"
+	"github.com/openacid/slim/marshal"
 	"github.com/openacid/slim/strhelper"
 )
 
@@ -408,6 +413,101 @@ func TestSlimTrieSearch(t *testing.T) {
 	}
 }
 
+func TestSlimTrieLeafOrdinal(t *testing.T) {
+
+	ctrie, err := NewSlimTrie(TestIntConv{}, searchKeys, searchValues)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	// valueAt returns the value at position `i` in Leaves or nil if i == -1.
+	valueAt := func(i int32) interface{} {
+		if i == -1 {
+			return nil
+		}
+		_, v := ctrie.Leaves.Unmarshal(ctrie.Leaves.Elts[i*8:])
+		return v
+	}
+
+	keys := []string{"abc", "abd", "bcd", "bce", "cde", "acb", "ab", "ac",
+		"abcde", "", "a", "z", "bcdef"}
+
+	for _, key := range keys {
+
+		wantlt, wanteq, wantgt := ctrie.Search(key)
+		lt, eq, gt := ctrie.SearchLeafOrdinal(key)
+
+		rst := searchRst{valueAt(lt), valueAt(eq), valueAt(gt)}
+		want := searchRst{wantlt, wanteq, wantgt}
+		if !reflect.DeepEqual(want, rst) {
+			t.Fatalf("key: %q; expected: %v; rst: %v", key, want, rst)
+		}
+
+		wantv := ctrie.Get(key)
+		i, found := ctrie.GetLeafOrdinal(key)
+		if found != (wantv != nil) {
+			t.Fatalf("key: %q; expected found: %v; rst: %v", key, wantv != nil, found)
+		}
+		if found && valueAt(int32(i)) != wantv {
+			t.Fatalf("key: %q; expected: %v; rst: %v", key, wantv, valueAt(int32(i)))
+		}
+	}
+}
+
+func TestSlimTriePrefixNode(t *testing.T) {
+
+	ctrie, err := NewSlimTrie(TestIntConv{}, searchKeys, searchValues)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	// subValues collects leaf values in the sub-trie of node `idx` in key
+	// order.
+	var subValues func(idx int32) []interface{}
+	subValues = func(idx int32) []interface{} {
+		vals := []interface{}{}
+		if v, found := ctrie.Leaves.Get2(uint32(idx)); found {
+			vals = append(vals, v)
+		}
+		for _, c := range ctrie.ChildNodes(idx) {
+			vals = append(vals, subValues(c)...)
+		}
+		return vals
+	}
+
+	cases := []struct {
+		prefix string
+		want   []interface{}
+	}{
+		{"", []interface{}{0, 1, 2, 3, 4, 5, 6, 7}},
+		{"a", []interface{}{0, 1, 2, 3}},
+		{"ab", []interface{}{0, 1, 2, 3}},
+		{"abc", []interface{}{0, 1}},
+		{"abd", []interface{}{2, 3}},
+		{"abde", []interface{}{3}},
+		{"bcd", []interface{}{5, 6}},
+		{"c", []interface{}{7}},
+		{"cde", []interface{}{7}},
+		{"d", nil},
+		{"abe", nil},
+	}
+
+	for i, c := range cases {
+		idx := ctrie.PrefixNode(c.prefix)
+		if c.want == nil {
+			if idx != -1 {
+				t.Fatalf("%d-th: prefix: %q; expect -1 but: %d", i+1, c.prefix, idx)
+			}
+			continue
+		}
+
+		rst := subValues(idx)
+		if !reflect.DeepEqual(c.want, rst) {
+			t.Fatalf("%d-th: prefix: %q; expected: %v; rst: %v", i+1, c.prefix, c.want, rst)
+		}
+	}
+}
+
 func TestSlimTrieMarshalUnmarshal(t *testing.T) {
 	key := [][]byte{
 		{1, 2, 3},
@@ -434,9 +534,9 @@ func TestSlimTrieMarshalUnmarshal(t *testing.T) {
 
 	rw := new(bytes.Buffer)
 
-	size := ctrie.getMarshalSize()
+	size := ctrie.GetMarshalSize()
 
-	n, err := ctrie.marshal(rw)
+	n, err := ctrie.Marshal(rw)
 	if err != nil {
 		t.Fatalf("failed to marshal ctrie: %v", err)
 	}
@@ -447,7 +547,7 @@ func TestSlimTrieMarshalUnmarshal(t *testing.T) {
 
 	// unmarshal
 	rCtrie, _ := NewSlimTrie(array.U16Conv{}, nil, nil)
-	err = rCtrie.unmarshal(rw)
+	err = rCtrie.Unmarshal(rw)
 	if err != nil {
 		t.Fatalf("failed to unmarshal: %v", err)
 	}
@@ -510,7 +610,7 @@ func TestSlimTrieMarshalAtUnmarshalAt(t *testing.T) {
 		t.Fatalf("failed to marshal ctrie: %v", err)
 	}
 
-	size := ctrie1.getMarshalSize()
+	size := ctrie1.GetMarshalSize()
 	if n != size {
 		t.Fatalf("wrong marshal size: %d, %d", n, size)
 	}
@@ -520,7 +620,7 @@ func TestSlimTrieMarshalAtUnmarshalAt(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to marshal ctrie: %v", err)
 	}
-	size = ctrie1.getMarshalSize()
+	size = ctrie1.GetMarshalSize()
 	if n != size {
 		t.Fatalf("wrong marshal size: %d, %d", n, size)
 	}
@@ -567,3 +667,314 @@ func checkSlimTrie(ctrie, rCtrie *SlimTrie, t *testing.T) {
 		t.Fatalf("Leaves not the same")
 	}
 }
+
+func TestSlimTrieLeaves(t *testing.T) {
+
+	type loc struct {
+		Shard  string `slim:"size=2"`
+		Offset uint64 `slim:"size=5"`
+		Hot    bool
+	}
+
+	keys := []string{"abc", "abcd", "abd", "abde", "bc", "bcd", "bcde", "cde"}
+
+	locs := make([]loc, len(keys))
+	for i := range keys {
+		locs[i] = loc{Shard: fmt.Sprintf("s%d", i), Offset: uint64(i) << 36, Hot: i%2 == 0}
+	}
+
+	structConv, err := marshal.Struct(loc{})
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	cases := []struct {
+		conv   array.Converter
+		values interface{}
+		// eltsSize is the expected size of Leaves.Elts, or -1 to skip.
+		eltsSize int
+	}{
+		// 8 leaves of 3 bits in one word
+		{array.PackedConv{Width: 3}, []uint64{0, 1, 2, 3, 4, 5, 6, 7}, 8},
+		{marshal.String16{}, []string{"", "a", "bb", "ccc", "a long value", "x", "", "last"}, -1},
+		{
+			array.DictConv{Converter: marshal.String16{}},
+			[]string{"hot", "cold", "hot", "hot", "cold", "warm", "hot", "cold"},
+			-1,
+		},
+		// 8 bytes per leaf
+		{structConv, locs, 8 * len(keys)},
+	}
+
+	for i, c := range cases {
+
+		ctrie, err := NewSlimTrie(c.conv, keys, c.values)
+		if err != nil {
+			t.Fatalf("%d-th: expect no error but: %s", i+1, err)
+		}
+
+		rw := new(bytes.Buffer)
+		if _, err := ctrie.Marshal(rw); err != nil {
+			t.Fatalf("%d-th: failed to marshal ctrie: %v", i+1, err)
+		}
+
+		loaded, _ := NewSlimTrie(c.conv, nil, nil)
+		if err := loaded.Unmarshal(rw); err != nil {
+			t.Fatalf("%d-th: failed to unmarshal: %v", i+1, err)
+		}
+
+		rv := reflect.ValueOf(c.values)
+		for _, st := range []*SlimTrie{ctrie, loaded} {
+			if c.eltsSize != -1 && len(st.Leaves.Elts) != c.eltsSize {
+				t.Fatalf("%d-th: expect %d bytes of leaves but: %d", i+1, c.eltsSize, len(st.Leaves.Elts))
+			}
+			for j, k := range keys {
+				want := rv.Index(j).Interface()
+				v := st.Get(k)
+				if !reflect.DeepEqual(want, v) {
+					t.Fatalf("%d-th: Get %q expect: %v but: %v", i+1, k, want, v)
+				}
+			}
+		}
+	}
+}
+
+type testTier uint8
+
+func TestSlimTrieInferConverter(t *testing.T) {
+
+	keys := []string{"abc", "abcd", "abd", "abde", "bc", "bcd", "bcde", "cde"}
+
+	cases := []struct {
+		values interface{}
+		want   array.Converter
+	}{
+		{[]uint8{1, 2, 3, 4, 5, 6, 7, 255}, marshal.U8{}},
+		{[]int64{-1, 2, -3, 4, -5, 6, -7, 8}, marshal.I64{}},
+		{[]float64{0.5, 1, 1.5, 2, -2.5, 3, 3.5, 4}, marshal.F64{}},
+		{[]bool{true, false, true, true, false, false, true, false}, marshal.Bool{}},
+		{[]string{"a", "bb", "", "ccc", "d", "ee", "f", "gg"}, marshal.String16{}},
+		{[][]byte{{1}, {2, 3}, {}, {4}, {5}, {6}, {7}, {8, 9}}, marshal.Bytes16{}},
+		{
+			[][2]uint16{{1, 2}, {3, 4}, {5, 6}, {7, 8}, {9, 10}, {11, 12}, {13, 14}, {15, 16}},
+			marshal.Array{Type: reflect.TypeOf([2]uint16{}), Elt: marshal.U16{}},
+		},
+		{
+			[]testTier{1, 2, 3, 4, 5, 6, 7, 8},
+			marshal.Named{Type: reflect.TypeOf(testTier(0)), Elt: marshal.U8{}},
+		},
+	}
+
+	for i, c := range cases {
+
+		ctrie, err := NewSlimTrie(nil, keys, c.values)
+		if err != nil {
+			t.Fatalf("%d-th: expect no error but: %s", i+1, err)
+		}
+		if ctrie.Leaves.Converter != c.want {
+			t.Fatalf("%d-th: expect converter: %#v but: %#v", i+1, c.want, ctrie.Leaves.Converter)
+		}
+
+		rw := new(bytes.Buffer)
+		if _, err := ctrie.Marshal(rw); err != nil {
+			t.Fatalf("%d-th: failed to marshal ctrie: %v", i+1, err)
+		}
+
+		// a registered converter is loaded by name, others must be specified.
+		var conv array.Converter
+		if _, ok := array.ConverterName(c.want); !ok {
+			conv = c.want
+		}
+
+		loaded, _ := NewSlimTrie(conv, nil, nil)
+		if err := loaded.Unmarshal(rw); err != nil {
+			t.Fatalf("%d-th: failed to unmarshal: %v", i+1, err)
+		}
+
+		rv := reflect.ValueOf(c.values)
+		for _, st := range []*SlimTrie{ctrie, loaded} {
+			for j, k := range keys {
+				want := rv.Index(j).Interface()
+				v := st.Get(k)
+				if !reflect.DeepEqual(want, v) {
+					t.Fatalf("%d-th: Get %q expect: %v but: %v", i+1, k, want, v)
+				}
+			}
+		}
+	}
+
+	_, err := NewSlimTrie(nil, keys, []int{1, 2, 3, 4, 5, 6, 7, 8})
+	if err != marshal.ErrUnknownEltType {
+		t.Fatalf("expect ErrUnknownEltType but: %v", err)
+	}
+}
+
+func TestSlimTrieConcurrentGet(t *testing.T) {
+
+	keys := []string{"abc", "abcd", "abd", "abde", "bc", "bcd", "bcde", "cde"}
+	values := []int64{0, 1, 2, 3, 4, 5, 6, 7}
+
+	st, err := NewSlimTrie(marshal.I64{}, keys, values)
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				j := (i + g) % len(keys)
+				v := st.Get(keys[j])
+				if v == nil || v.(int64) != values[j] {
+					t.Errorf("Get %q expect: %d but: %v", keys[j], values[j], v)
+					return
+				}
+				st.Search(keys[j] + "0")
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestSlimTrieSearchExact(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(0))
+
+	// a few distinct bytes make long shared words, which SlimTrie skips.
+	alphabet := "aabcR\x00\xff"
+	randKey := func() string {
+		b := make([]byte, rnd.Intn(7))
+		for i := range b {
+			b[i] = alphabet[rnd.Intn(len(alphabet))]
+		}
+		return string(b)
+	}
+
+	for round := 0; round < 200; round++ {
+
+		// SlimTrie does not support empty key
+		set := map[string]bool{}
+		for i := 0; i < 1+rnd.Intn(40); i++ {
+			if k := randKey(); k != "" {
+				set[k] = true
+			}
+		}
+		if len(set) == 0 {
+			continue
+		}
+		keys := []string{}
+		for k := range set {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		values := make([]uint32, len(keys))
+		for i := range values {
+			values[i] = uint32(i)
+		}
+
+		st, err := NewSlimTrie(marshal.U32{}, keys, values)
+		if err != nil {
+			t.Fatalf("expect no error but: %s", err)
+		}
+
+		byOrdinal := map[int32]string{}
+		for _, k := range keys {
+			ord, _ := st.GetLeafOrdinal(k)
+			byOrdinal[int32(ord)] = k
+		}
+
+		reads := 0
+		keyOf := func(v interface{}) (string, bool) {
+			reads++
+			return keys[v.(uint32)], true
+		}
+		keyOfOrdinal := func(ord int32) (string, bool) {
+			return byOrdinal[ord], true
+		}
+
+		queries := append([]string{}, keys...)
+		for i := 0; i < 100; i++ {
+			queries = append(queries, randKey())
+		}
+
+		for _, q := range queries {
+
+			wantLt, wantEq, wantGt := -1, -1, -1
+			i := sort.SearchStrings(keys, q)
+			if i > 0 {
+				wantLt = i - 1
+			}
+			if i < len(keys) && keys[i] == q {
+				wantEq = i
+				i++
+			}
+			if i < len(keys) {
+				wantGt = i
+			}
+
+			reads = 0
+			lt, eq, gt := st.SearchExact(q, keyOf)
+			if reads != 1 {
+				t.Fatalf("expect 1 key read but: %d", reads)
+			}
+
+			for _, c := range []struct {
+				name string
+				want int
+				act  interface{}
+			}{
+				{"lt", wantLt, lt}, {"eq", wantEq, eq}, {"gt", wantGt, gt},
+			} {
+				act := -1
+				if c.act != nil {
+					act = int(c.act.(uint32))
+				}
+				if act != c.want {
+					t.Fatalf("keys: %q; query: %q; %s want: %d; actual: %d",
+						keys, q, c.name, c.want, act)
+				}
+			}
+
+			ltOrd, eqOrd, gtOrd := st.SearchLeafOrdinalExact(q, keyOfOrdinal)
+			for _, c := range []struct {
+				want int
+				act  int32
+			}{
+				{wantLt, ltOrd}, {wantEq, eqOrd}, {wantGt, gtOrd},
+			} {
+				if (c.want == -1) != (c.act == -1) || c.act != -1 && byOrdinal[c.act] != keys[c.want] {
+					t.Fatalf("keys: %q; query: %q; want: %d; actual ordinal: %d",
+						keys, q, c.want, c.act)
+				}
+			}
+		}
+	}
+
+	empty, err := NewSlimTrie(marshal.I64{}, []string{}, []int64{})
+	if err != nil {
+		t.Fatalf("expect no error but: %s", err)
+	}
+
+	keyOf := func(v interface{}) (string, bool) {
+		t.Fatalf("expect no key read from empty trie")
+		return "", false
+	}
+
+	for _, q := range []string{"", "a", "abc"} {
+		lt, eq, gt := empty.SearchExact(q, keyOf)
+		if lt != nil || eq != nil || gt != nil {
+			t.Fatalf("query: %q; expect nil, nil, nil but: %v, %v, %v", q, lt, eq, gt)
+		}
+
+		ltOrd, eqOrd, gtOrd := empty.SearchLeafOrdinalExact(q, func(ord int32) (string, bool) {
+			t.Fatalf("expect no key read from empty trie")
+			return "", false
+		})
+		if ltOrd != -1 || eqOrd != -1 || gtOrd != -1 {
+			t.Fatalf("query: %q; expect -1, -1, -1 but: %d, %d, %d", q, ltOrd, eqOrd, gtOrd)
+		}
+	}
+}